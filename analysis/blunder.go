@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"hunsuChess/engine"
+	"hunsuChess/tactics"
+
+	"github.com/notnil/chess"
+)
+
+const (
+	// Material a move may leave en prise before it counts as a blunder, in centipawns.
+	blunderMaterial = 200
+	// Evaluation a move may lose against the engine's choice before it counts as a blunder.
+	blunderDrop = 300
+	// Number of moves of the refutation kept for display.
+	refutationLength = 4
+)
+
+// Warning explains why a move looks like a blunder.
+type Warning struct {
+	Move string // UCI
+	SAN  string
+	// Mate is the number of moves in which the opponent mates after the move, 0 if none was found.
+	Mate int
+	// Loss is the material or evaluation the move gives away, in centipawns.
+	Loss int
+	// Refutation is the opponent's best reply and its continuation in UCI.
+	Refutation []string
+	// RefutationSAN is Refutation in algebraic notation.
+	RefutationSAN []string
+}
+
+// CheckMove looks for a quick refutation of the move. A mate in one found
+// statically is always reported; otherwise the engine, if a is not nil,
+// decides whether the move allows a mate or loses too much evaluation, and
+// without an engine a piece left en prise counts as a blunder.
+// It returns nil if the move looks safe.
+func CheckMove(ctx context.Context, a engine.Analyzer, limits engine.Limits, pos *chess.Position, move string) (*Warning, error) {
+	m, err := decode(pos, move)
+	if err != nil {
+		return nil, err
+	}
+	next := pos.Update(m)
+	if next.Status() != chess.NoMethod {
+		// The move ends the game, there is nothing to refute.
+		return nil, nil
+	}
+
+	w := &Warning{Move: move, SAN: chess.AlgebraicNotation{}.Encode(pos, m)}
+	mover := pos.Turn()
+	hints := tactics.Analyze(next)
+
+	for _, hint := range hints {
+		if hint.Kind == tactics.MateThreat && hint.Color == mover {
+			w.Mate = 1
+			return w.refute(next, []string{hint.Move}), nil
+		}
+	}
+
+	if a != nil {
+		return checkWithEngine(ctx, a, limits, pos, next, w)
+	}
+
+	if m.HasTag(chess.Check) {
+		// The opponent has to answer the check first, so what is en prise may not be lost.
+		return nil, nil
+	}
+	var worst *tactics.Hint
+	for i, hint := range hints {
+		if (hint.Kind == tactics.Hanging || hint.Kind == tactics.Attacked) && hint.Color == mover {
+			if worst == nil || hint.Loss > worst.Loss {
+				worst = &hints[i]
+			}
+		}
+	}
+	if worst == nil {
+		return nil, nil
+	}
+	w.Loss = worst.Loss - captured(pos, m)
+	if w.Loss < blunderMaterial {
+		return nil, nil
+	}
+	return w.refute(next, []string{cheapestCapture(next, *worst)}), nil
+}
+
+func checkWithEngine(ctx context.Context, a engine.Analyzer, limits engine.Limits, pos *chess.Position, next *chess.Position, w *Warning) (*Warning, error) {
+	reply, err := analyze(ctx, a, next, limits)
+	if err != nil {
+		return nil, fmt.Errorf("analyze reply: %w", err)
+	}
+	if len(reply) == 0 {
+		return nil, nil
+	}
+	if reply[0].Score.Mate > 0 {
+		w.Mate = reply[0].Score.Mate
+		return w.refute(next, reply[0].PV), nil
+	}
+
+	best, err := analyze(ctx, a, pos, limits)
+	if err != nil {
+		return nil, fmt.Errorf("analyze position: %w", err)
+	}
+	if len(best) == 0 || best[0].Score.Mate != 0 {
+		// Comparing against a mate, won or lost anyway, says nothing about material.
+		return nil, nil
+	}
+	w.Loss = best[0].Score.CP - reply[0].Score.Negate().CP
+	if w.Loss < blunderDrop {
+		return nil, nil
+	}
+	return w.refute(next, reply[0].PV), nil
+}
+
+// refute sets the refutation starting from pos, the position after the checked move.
+func (w *Warning) refute(pos *chess.Position, line []string) *Warning {
+	for _, move := range line {
+		if len(w.Refutation) == refutationLength {
+			break
+		}
+		m, err := decode(pos, move)
+		if err != nil {
+			break
+		}
+		w.Refutation = append(w.Refutation, move)
+		w.RefutationSAN = append(w.RefutationSAN, chess.AlgebraicNotation{}.Encode(pos, m))
+		pos = pos.Update(m)
+	}
+	return w
+}
+
+// captured returns the value of the material the move wins.
+func captured(pos *chess.Position, m *chess.Move) int {
+	gain := 0
+	if m.HasTag(chess.EnPassant) {
+		gain = engine.PieceValue(chess.Pawn)
+	} else if p := pos.Board().Piece(m.S2()); p != chess.NoPiece {
+		gain = engine.PieceValue(p.Type())
+	}
+	if m.Promo() != chess.NoPieceType {
+		gain += engine.PieceValue(m.Promo()) - engine.PieceValue(chess.Pawn)
+	}
+	return gain
+}
+
+// cheapestCapture returns the capture of the hinted piece by its least valuable attacker.
+func cheapestCapture(pos *chess.Position, hint tactics.Hint) string {
+	from := hint.From[0]
+	for _, sq := range hint.From[1:] {
+		if engine.PieceValue(pos.Board().Piece(sq).Type()) < engine.PieceValue(pos.Board().Piece(from).Type()) {
+			from = sq
+		}
+	}
+	move := from.String() + hint.Square.String()
+	if pos.Board().Piece(from).Type() == chess.Pawn && (hint.Square.Rank() == chess.Rank1 || hint.Square.Rank() == chess.Rank8) {
+		move += "q"
+	}
+	return move
+}
@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"hunsuChess/engine"
+	"hunsuChess/engine/enginetest"
+)
+
+func TestCheckMoveStatic(t *testing.T) {
+	tests := []struct {
+		name       string
+		moves      []string // played before the checked move
+		move       string
+		mate       int
+		loss       int
+		refutation []string
+	}{
+		{"allows mate in one", []string{"f3", "e5"}, "g2g4", 1, 0, []string{"d8h4"}},
+		{"leaves the queen", []string{"e4", "d5"}, "d1g4", 0, 900, []string{"c8g4"}},
+		{"safe", nil, "e2e4", 0, 0, nil},
+		{"defended piece", []string{"e4", "e5"}, "g1f3", 0, 0, nil},
+		{"ends the game", []string{"f3", "e5", "g4"}, "d8h4", 0, 0, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, positions := playGame(t, test.moves...)
+			w, err := CheckMove(context.Background(), nil, engine.Limits{}, positions[len(positions)-1], test.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.refutation == nil {
+				if w != nil {
+					t.Errorf("warning %+v for a safe move", w)
+				}
+				return
+			}
+			if w == nil {
+				t.Fatal("no warning")
+			}
+			if w.Mate != test.mate || w.Loss < test.loss || !reflect.DeepEqual(w.Refutation, test.refutation) {
+				t.Errorf("warning %+v, want mate %d, loss %d and refutation %v", w, test.mate, test.loss, test.refutation)
+			}
+		})
+	}
+}
+
+func TestCheckMoveEngine(t *testing.T) {
+	_, positions := playGame(t, "e4", "e5")
+	pos := positions[len(positions)-1]
+	next := func(move string) string {
+		m, err := decode(pos, move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return enginetest.Key(pos.Update(m).String())
+	}
+
+	tests := []struct {
+		name  string
+		move  string
+		reply []string // the engine's answer after the move
+		mate  int
+		loss  int
+		warns bool
+	}{
+		{"allows a mate", "f2f3", answer("d8h4", "mate 2"), 2, 0, true},
+		{"loses material", "d1h5", answer("g7g6", "cp 300"), 0, 350, true},
+		{"small loss", "b1c3", answer("g8f6", "cp 100"), 0, 0, false},
+	}
+	script := enginetest.Script{enginetest.Key(pos.String()): answer("g1f3", "cp 50")}
+	for _, test := range tests {
+		script[next(test.move)] = test.reply
+	}
+	pool := fakePool(t, script)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, err := CheckMove(context.Background(), pool, engine.Limits{Depth: 10}, pos, test.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.warns {
+				if w != nil {
+					t.Errorf("warning %+v, want none", w)
+				}
+				return
+			}
+			if w == nil || w.Mate != test.mate || w.Loss != test.loss || len(w.Refutation) == 0 {
+				t.Errorf("warning %+v, want mate %d and loss %d", w, test.mate, test.loss)
+			}
+		})
+	}
+}
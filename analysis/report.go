@@ -0,0 +1,313 @@
+// Package analysis reviews finished games with an engine.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+// Class rates a played move by how much winning chance it gave away.
+type Class int
+
+const (
+	Best Class = iota
+	Good
+	Inaccuracy
+	Mistake
+	Blunder
+)
+
+func (c Class) String() string {
+	switch c {
+	case Best:
+		return "best"
+	case Good:
+		return "good"
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	}
+	return "blunder"
+}
+
+// Korean name of the class, used in the report posted to Discord.
+func (c Class) Name() string {
+	switch c {
+	case Best:
+		return "최선"
+	case Good:
+		return "좋은 수"
+	case Inaccuracy:
+		return "부정확"
+	case Mistake:
+		return "실수"
+	}
+	return "대실수"
+}
+
+// Evaluations beyond this many centipawns are treated as decided.
+const evalCap = 1000
+
+// Ply is the review of one played move.
+type Ply struct {
+	Number   int // 1 for white's first move
+	Color    chess.Color
+	Move     string // UCI
+	SAN      string
+	Best     string // engine's preferred move in UCI
+	BestSAN  string
+	Before   engine.Score // evaluation before the move, from white
+	After    engine.Score // evaluation after the move, from white
+	Class    Class
+	Accuracy float64  // 0 to 100
+	Backers  []string // voters who had voted for the engine's preferred move
+}
+
+// Report is the review of a whole game.
+type Report struct {
+	Plies         []Ply
+	WhiteAccuracy float64
+	BlackAccuracy float64
+	Outcome       chess.Outcome
+}
+
+// Review analyzes every move of the game. votes[i] maps voters to the move
+// they voted for at ply i, and may be shorter than the game or nil.
+func Review(ctx context.Context, a engine.Analyzer, limits engine.Limits, g *chess.Game, votes []map[string]string) (*Report, error) {
+	positions := g.Positions()
+	moves := g.Moves()
+
+	// Evaluate every position before its move, from the side to move.
+	scores := make([]engine.Score, len(moves))
+	bests := make([]string, len(moves))
+	for i := range moves {
+		lines, err := analyze(ctx, a, positions[i], limits)
+		if err != nil {
+			return nil, fmt.Errorf("analyze ply %d: %w", i+1, err)
+		}
+		if len(lines) > 0 {
+			scores[i] = lines[0].Score
+			bests[i] = lines[0].Move
+		}
+	}
+
+	// The played moves are searched one ply shallower, so their scores stay
+	// comparable with the engine's own choice at the same depth.
+	played := make([]engine.Score, len(moves))
+	childLimits := limits
+	if childLimits.Depth > 1 {
+		childLimits.Depth--
+	}
+	for i, move := range moves {
+		next := positions[i+1]
+		switch {
+		case move.String() == bests[i]:
+			played[i] = scores[i]
+		case len(next.ValidMoves()) == 0:
+			// Mate for the mover, or a stalemate which stays at zero.
+			if next.Status() == chess.Checkmate {
+				played[i] = engine.Score{Won: true}
+			}
+		default:
+			lines, err := analyze(ctx, a, next, childLimits)
+			if err != nil {
+				return nil, fmt.Errorf("analyze ply %d: %w", i+1, err)
+			}
+			if len(lines) > 0 {
+				played[i] = lines[0].Score.Negate()
+			}
+		}
+	}
+
+	report := &Report{Outcome: g.Outcome()}
+	var whiteSum, blackSum float64
+	var whiteCount, blackCount int
+
+	for i, move := range moves {
+		pos := positions[i]
+		mover := pos.Turn()
+
+		before := scores[i]
+		after := played[i]
+		wpBefore := winPercent(before)
+		wpAfter := winPercent(after)
+
+		ply := Ply{
+			Number:   i + 1,
+			Color:    mover,
+			Move:     move.String(),
+			SAN:      chess.AlgebraicNotation{}.Encode(pos, move),
+			Best:     bests[i],
+			Accuracy: moveAccuracy(wpBefore, wpAfter),
+		}
+		if m, err := decode(pos, bests[i]); err == nil {
+			ply.BestSAN = chess.AlgebraicNotation{}.Encode(pos, m)
+		}
+		if mover == chess.White {
+			ply.Before, ply.After = before, after
+		} else {
+			ply.Before, ply.After = before.Negate(), after.Negate()
+		}
+		ply.Class = classify(move.String() == bests[i], wpBefore-wpAfter)
+
+		if i < len(votes) {
+			for voter, voted := range votes[i] {
+				if voted == bests[i] {
+					ply.Backers = append(ply.Backers, voter)
+				}
+			}
+		}
+
+		if mover == chess.White {
+			whiteSum += ply.Accuracy
+			whiteCount++
+		} else {
+			blackSum += ply.Accuracy
+			blackCount++
+		}
+		report.Plies = append(report.Plies, ply)
+	}
+
+	if whiteCount > 0 {
+		report.WhiteAccuracy = whiteSum / float64(whiteCount)
+	}
+	if blackCount > 0 {
+		report.BlackAccuracy = blackSum / float64(blackCount)
+	}
+	return report, nil
+}
+
+// Evals returns the evaluation after each ply from white's point of view in
+// pawns, clamped for drawing.
+func (r *Report) Evals() []float64 {
+	evals := make([]float64, 0, len(r.Plies)+1)
+	evals = append(evals, 0)
+	for _, ply := range r.Plies {
+		evals = append(evals, float64(clampCP(ply.After))/100)
+	}
+	return evals
+}
+
+// Count returns how many moves of the color got the class.
+func (r *Report) Count(color chess.Color, class Class) int {
+	n := 0
+	for _, ply := range r.Plies {
+		if ply.Color == color && ply.Class == class {
+			n++
+		}
+	}
+	return n
+}
+
+// PGN encodes the game with the review of each move as comments.
+// names maps voter IDs to display names, unknown IDs are written as is.
+func (r *Report) PGN(tags map[string]string, names map[string]string) string {
+	var sb strings.Builder
+	for _, key := range []string{"Event", "Site", "Date", "White", "Black", "Result", "ECO", "Opening"} {
+		if value, ok := tags[key]; ok {
+			fmt.Fprintf(&sb, "[%s \"%s\"]\n", key, value)
+		}
+	}
+	fmt.Fprintf(&sb, "[WhiteAccuracy \"%.1f\"]\n[BlackAccuracy \"%.1f\"]\n\n", r.WhiteAccuracy, r.BlackAccuracy)
+
+	for _, ply := range r.Plies {
+		// Every move carries a comment, so black's moves repeat the move number.
+		if ply.Color == chess.White {
+			fmt.Fprintf(&sb, "%d. ", (ply.Number+1)/2)
+		} else {
+			fmt.Fprintf(&sb, "%d... ", (ply.Number+1)/2)
+		}
+		fmt.Fprintf(&sb, "%s { %s } ", ply.SAN, ply.comment(names))
+	}
+	sb.WriteString(string(r.Outcome))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func (p Ply) comment(names map[string]string) string {
+	parts := []string{fmt.Sprintf("[%%eval %s]", p.After), p.Class.String()}
+	if p.Class != Best && p.BestSAN != "" {
+		parts = append(parts, "best "+p.BestSAN)
+	}
+	if len(p.Backers) > 0 {
+		backers := make([]string, len(p.Backers))
+		for i, id := range p.Backers {
+			backers[i] = id
+			if name, ok := names[id]; ok {
+				backers[i] = name
+			}
+		}
+		parts = append(parts, "backed by "+strings.Join(backers, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func classify(isBest bool, drop float64) Class {
+	switch {
+	case isBest:
+		return Best
+	case drop >= 15:
+		return Blunder
+	case drop >= 10:
+		return Mistake
+	case drop >= 5:
+		return Inaccuracy
+	}
+	return Good
+}
+
+func clampCP(s engine.Score) int {
+	switch {
+	case s.Won, s.Mate > 0:
+		return evalCap
+	case s.Mated, s.Mate < 0:
+		return -evalCap
+	case s.CP > evalCap:
+		return evalCap
+	case s.CP < -evalCap:
+		return -evalCap
+	}
+	return s.CP
+}
+
+// winPercent converts an evaluation to the chance of winning, from 0 to 100.
+func winPercent(s engine.Score) float64 {
+	cp := float64(clampCP(s))
+	return 50 + 50*(2/(1+math.Exp(-0.00368208*cp))-1)
+}
+
+// moveAccuracy rates a move from 0 to 100 by the winning chance it lost.
+func moveAccuracy(before, after float64) float64 {
+	if after >= before {
+		return 100
+	}
+	accuracy := 103.1668*math.Exp(-0.04354*(before-after)) - 3.1669
+	return math.Max(0, math.Min(100, accuracy))
+}
+
+func analyze(ctx context.Context, a engine.Analyzer, pos *chess.Position, limits engine.Limits) ([]engine.Line, error) {
+	limits.MultiPV = 1
+	return a.Analyze(ctx, pos.String(), limits)
+}
+
+// decode returns the legal move of the position, which unlike the decoded
+// move also carries tags such as check.
+func decode(pos *chess.Position, uci string) (*chess.Move, error) {
+	m, err := chess.UCINotation{}.Decode(pos, uci)
+	if err != nil {
+		return nil, err
+	}
+	for _, valid := range pos.ValidMoves() {
+		if valid.S1() == m.S1() && valid.S2() == m.S2() && valid.Promo() == m.Promo() {
+			return valid, nil
+		}
+	}
+	return nil, fmt.Errorf("illegal move %s", uci)
+}
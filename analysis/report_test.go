@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"hunsuChess/engine"
+	"hunsuChess/engine/enginetest"
+
+	"github.com/notnil/chess"
+)
+
+func TestMain(m *testing.M) {
+	enginetest.Main(m)
+}
+
+// answer is what the fake engine writes for a position whose best line
+// starts with move and has the score, e.g. "cp 30" or "mate 1".
+func answer(move string, score string) []string {
+	return []string{
+		fmt.Sprintf("info depth 10 multipv 1 score %s pv %s", score, move),
+		"bestmove " + move,
+	}
+}
+
+// fakePool starts the fake engine answering the positions of the script.
+func fakePool(t *testing.T, script enginetest.Script) *engine.Pool {
+	t.Helper()
+	path, env := enginetest.Command(t, enginetest.Options{Script: script})
+	pool, err := engine.NewPool(engine.Config{Path: path, Env: env, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// playGame plays the moves in algebraic notation and returns the positions.
+func playGame(t *testing.T, moves ...string) (*chess.Game, []*chess.Position) {
+	t.Helper()
+	g := chess.NewGame()
+	for _, move := range moves {
+		if err := g.MoveStr(move); err != nil {
+			t.Fatalf("move %s: %v", move, err)
+		}
+	}
+	return g, g.Positions()
+}
+
+func TestWinPercent(t *testing.T) {
+	tests := []struct {
+		score engine.Score
+		want  float64
+	}{
+		{engine.Score{}, 50},
+		{engine.Score{CP: 100}, 59.1},
+		{engine.Score{CP: -100}, 40.9},
+		{engine.Score{CP: 300}, 75.1},
+		{engine.Score{CP: 5000}, 97.5},
+		{engine.Score{Mate: 3}, 97.5},
+		{engine.Score{Mate: -1}, 2.5},
+		{engine.Score{Won: true}, 97.5},
+		{engine.Score{Mated: true}, 2.5},
+	}
+	for _, test := range tests {
+		if got := winPercent(test.score); math.Abs(got-test.want) > 0.05 {
+			t.Errorf("winPercent(%+v) = %.2f, want %.1f", test.score, got, test.want)
+		}
+	}
+}
+
+func TestMoveAccuracy(t *testing.T) {
+	tests := []struct {
+		before, after float64
+		want          float64
+	}{
+		{50, 50, 100},
+		{50, 60, 100},
+		{50, 45, 79.8},
+		{50, 40, 63.6},
+		{60, 30, 24.8},
+		{97.5, 2.5, 0},
+	}
+	for _, test := range tests {
+		if got := moveAccuracy(test.before, test.after); math.Abs(got-test.want) > 0.05 {
+			t.Errorf("moveAccuracy(%v, %v) = %.2f, want %.1f", test.before, test.after, got, test.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		best bool
+		drop float64
+		want Class
+	}{
+		{true, 40, Best},
+		{false, -3, Good},
+		{false, 4.9, Good},
+		{false, 5, Inaccuracy},
+		{false, 9.9, Inaccuracy},
+		{false, 10, Mistake},
+		{false, 14.9, Mistake},
+		{false, 15, Blunder},
+		{false, 80, Blunder},
+	}
+	for _, test := range tests {
+		if got := classify(test.best, test.drop); got != test.want {
+			t.Errorf("classify(%v, %v) = %v, want %v", test.best, test.drop, got, test.want)
+		}
+	}
+}
+
+func TestReview(t *testing.T) {
+	// 1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6?? 4. Qxf7#
+	g, positions := playGame(t, "e4", "e5", "Qh5", "Nc6", "Bc4", "Nf6", "Qxf7#")
+	key := func(i int) string { return enginetest.Key(positions[i].String()) }
+	pool := fakePool(t, enginetest.Script{
+		key(0): answer("e2e4", "cp 30"),
+		key(1): answer("e7e5", "cp -30"),
+		key(2): answer("g1f3", "cp 40"),
+		key(3): answer("b8c6", "cp 0"),
+		key(4): answer("f1c4", "cp 20"),
+		key(5): answer("g7g6", "cp -20"),
+		key(6): answer("h5f7", "mate 1"),
+	})
+
+	votes := []map[string]string{{"alice": "e2e4", "bob": "d2d4"}}
+	report, err := Review(context.Background(), pool, engine.Limits{Depth: 10}, g, votes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Class{Best, Best, Good, Best, Best, Blunder, Best}
+	if len(report.Plies) != len(want) {
+		t.Fatalf("got %d plies, want %d", len(report.Plies), len(want))
+	}
+	for i, ply := range report.Plies {
+		if ply.Class != want[i] {
+			t.Errorf("ply %d %s is %v, want %v", ply.Number, ply.SAN, ply.Class, want[i])
+		}
+	}
+
+	if backers := report.Plies[0].Backers; len(backers) != 1 || backers[0] != "alice" {
+		t.Errorf("backers of 1. e4 = %v, want [alice]", backers)
+	}
+	blunder := report.Plies[5]
+	if blunder.BestSAN != "g6" || blunder.After != (engine.Score{Mate: 1}) || blunder.Accuracy > 15 {
+		t.Errorf("review of 3... Nf6 = %+v", blunder)
+	}
+	if report.WhiteAccuracy <= report.BlackAccuracy {
+		t.Errorf("accuracy white %.1f, black %.1f, want white ahead", report.WhiteAccuracy, report.BlackAccuracy)
+	}
+	if report.Count(chess.Black, Blunder) != 1 || report.Outcome != chess.WhiteWon {
+		t.Errorf("black blunders %d, outcome %s", report.Count(chess.Black, Blunder), report.Outcome)
+	}
+
+	pgn := report.PGN(map[string]string{"Event": "test"}, map[string]string{"alice": "Alice"})
+	for _, part := range []string{`[Event "test"]`, "3... Nf6 { [%eval #1], blunder, best g6 }", "backed by Alice", "Qxf7#", "1-0"} {
+		if !strings.Contains(pgn, part) {
+			t.Errorf("PGN lacks %q:\n%s", part, pgn)
+		}
+	}
+}
+
+func TestReviewMateOffBook(t *testing.T) {
+	// The engine missed the mate, the move played still ends the game.
+	g, positions := playGame(t, "f3", "e5", "g4", "Qh4#")
+	key := func(i int) string { return enginetest.Key(positions[i].String()) }
+	pool := fakePool(t, enginetest.Script{
+		key(0): answer("e2e4", "cp 30"),
+		key(1): answer("e7e5", "cp 80"),
+		key(2): answer("e2e4", "cp -90"),
+		key(3): answer("d8e7", "cp 50"),
+	})
+
+	report, err := Review(context.Background(), pool, engine.Limits{Depth: 10}, g, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := report.Plies[3]
+	if last.Class != Good || !last.After.Mated {
+		t.Errorf("2... Qh4# = %+v, want good and white mated", last)
+	}
+}
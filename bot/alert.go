@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hunsuChess/analysis"
+	"hunsuChess/chess"
+	"hunsuChess/engine"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long checking the leading moves for blunders may take.
+const alertTimeout = 10 * time.Minute
+
+// BlunderAlert checks the moves leading the vote of the team to move and
+// warns the team's channel about those that lose material or allow a mate.
+func (bot *Bot) BlunderAlert() {
+	if bot.session == nil || bot.game.IsGameOver() {
+		return
+	}
+	channelID := bot.game.TurnChannelID()
+	if channelID == "" {
+		return
+	}
+
+	analyzer := engine.Analyzer(bot.game.Searcher)
+	limits := engine.Limits{Depth: 4, MoveTime: 5 * time.Second}
+	if bot.game.Engine != nil {
+		analyzer = bot.game.Engine
+		limits = engine.Limits{Depth: 16, MoveTime: 2 * time.Second}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancel()
+
+	pos := bot.game.ChessGame.Position()
+	for _, move := range bot.game.LeadingMoves() {
+		warning, err := analysis.CheckMove(ctx, analyzer, limits, pos, move)
+		if err != nil {
+			fmt.Printf("Cannot check %s for blunders: %v\n", move, err)
+			continue
+		}
+		if warning == nil {
+			continue
+		}
+
+		message := &discordgo.MessageSend{
+			Content: blunderMessage(warning, time.Until(bot.game.NextTime)),
+			Files: []*discordgo.File{
+				{
+					Name:        "blunder.png",
+					ContentType: "image/png",
+					Reader: chess.Render(pos, chess.RenderOptions{
+						Orientation: pos.Turn(),
+						Arrows:      append([]string{warning.Move}, warning.Refutation...),
+					}),
+				},
+			},
+		}
+		if _, err := bot.session.ChannelMessageSendComplex(channelID, message); err != nil {
+			fmt.Printf("Cannot post blunder alert in %s: %v\n", channelID, err)
+		}
+	}
+}
+
+func blunderMessage(w *analysis.Warning, left time.Duration) string {
+	var problem string
+	switch {
+	case w.Mate == 1:
+		problem = "상대에게 바로 체크메이트를 허용합니다"
+	case w.Mate > 0:
+		problem = fmt.Sprintf("상대에게 %d수 만에 체크메이트를 허용합니다", w.Mate)
+	default:
+		problem = fmt.Sprintf("약 %.1f점의 손해를 봅니다", float64(w.Loss)/100)
+	}
+	return fmt.Sprintf("⚠️ 현재 가장 많은 표를 받은 **%s**는 %s.\n예상 진행: %s %s\n턴 마감까지 %d분 남았습니다. 다시 한번 검토해 주세요.",
+		w.SAN, problem, w.SAN, strings.Join(w.RefutationSAN, " "), int(left.Minutes()))
+}
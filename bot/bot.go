@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
+	"hunsuChess/chess"
 	"hunsuChess/game"
 	"hunsuChess/handlers"
 
@@ -15,12 +18,13 @@ import (
 type Bot struct {
 	game               *game.Game
 	interactionHandler *handlers.InteractionHandler
+	session            *discordgo.Session
 }
 
-func NewBot(game *game.Game) *Bot {
+func NewBot(game *game.Game, themes *chess.ThemeSettings) *Bot {
 	return &Bot{
 		game:               game,
-		interactionHandler: &handlers.InteractionHandler{Game: game},
+		interactionHandler: &handlers.InteractionHandler{Game: game, Themes: themes},
 	}
 }
 
@@ -38,6 +42,7 @@ func (bot *Bot) Start(token string) {
 		fmt.Printf("err by session open : %v\n", err)
 		return
 	}
+	bot.session = session
 
 	bot.addSlashCommands(session)
 
@@ -50,6 +55,12 @@ func (bot *Bot) Start(token string) {
 	<-sc
 }
 
+var adminPermission int64 = discordgo.PermissionManageServer
+var minRunoffSize float64 = 2
+var minBotStrength float64 = game.MinBotStrength
+var minReplayPly float64 = 0
+var minReplayDelay float64 = 0.1
+
 var (
 	commands = []*discordgo.ApplicationCommand{
 		{
@@ -64,6 +75,212 @@ var (
 			Name:        "join",
 			Description: "니트로 유저는 백, 무료 유저는 흑으로 팀이 자동 배정됩니다. 니트로는 부스트를 사용한 서버에서 참여할 수 있습니다.",
 		},
+		{
+			Name:        "reasons",
+			Description: "팀원들이 투표에 남긴 이유를 보고 공감을 누릅니다.",
+		},
+		{
+			Name:        "propose",
+			Description: "제안 단계에서 이유와 함께 수를 후보로 추천합니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "move",
+					Description: "추천할 수 (SAN 또는 UCI 형식)",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "reason",
+					Description: "추천하는 이유 (한 줄)",
+					Required:    true,
+					MaxLength:   100,
+				},
+			},
+		},
+		{
+			Name:        "delegate",
+			Description: "팀원에게 투표를 위임합니다. 대상을 비우면 위임을 취소합니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "투표를 위임할 팀원",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "captain",
+			Description: "팀 주장 선거에 투표합니다. 팀원 과반의 표를 얻으면 주장이 됩니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "주장으로 지지할 팀원",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "appoint",
+			Description:              "플레이어를 소속 팀의 주장으로 임명합니다.",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "주장으로 임명할 플레이어",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "veto",
+			Description: "주장 전용: 이번 턴에 한 개의 수를 후보에서 제외합니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "move",
+					Description: "거부할 수 (SAN 또는 UCI 형식)",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "hint",
+			Description: "현재 국면에서 공격받는 기물, 핀, 메이트 위협을 알려줍니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "overlay",
+					Description: "보드 이미지에 표시합니다. (기본값: 켜짐)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "coach",
+			Description: "코치 상담에 찬성합니다. 팀원 과반이 찬성하면 엔진의 추천 수 3개를 팀에게만 보여줍니다.",
+		},
+		{
+			Name:        "replay",
+			Description: "지금까지의 게임을 움직이는 GIF로 다시 봅니다.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "from",
+					Description: "시작할 반수 (0은 처음 배치)",
+					Required:    false,
+					MinValue:    &minReplayPly,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "to",
+					Description: "마지막 반수 (기본값: 마지막 수)",
+					Required:    false,
+					MinValue:    &minReplayPly,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "delay",
+					Description: "한 장면을 보여 줄 시간(초), 기본값 1초",
+					Required:    false,
+					MinValue:    &minReplayDelay,
+					MaxValue:    10,
+				},
+			},
+		},
+		{
+			Name:        "history",
+			Description: "최근 턴의 기록과 주장의 행동을 확인합니다.",
+		},
+		{
+			Name:        "theme",
+			Description: "/game과 미리보기에 쓰일 보드 테마, 기물 세트와 크기를 고릅니다.",
+		},
+		{
+			Name:                     "servertheme",
+			Description:              "서버의 기본 보드 테마와 기물 세트를 정합니다.",
+			DefaultMemberPermissions: &adminPermission,
+		},
+		{
+			Name:                     "runoff",
+			Description:              "제안 단계 후 상위 후보끼리 결선 투표를 하는 방식을 설정합니다.",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "결선 투표 사용 여부",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "candidates",
+					Description: "결선에 오르는 후보 수",
+					Required:    false,
+					MinValue:    &minRunoffSize,
+					MaxValue:    5,
+				},
+			},
+		},
+		{
+			Name:                     "evalbar",
+			Description:              "/game의 보드 옆에 엔진의 평가 막대를 표시할지 정합니다.",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "enabled",
+					Description: "평가 막대 사용 여부",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     "vsbot",
+			Description:              "커뮤니티가 한 색을 맡고 엔진이 다른 색을 두는 새 게임을 시작합니다.",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "color",
+					Description: "커뮤니티가 맡을 색",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "백", Value: "white"},
+						{Name: "흑", Value: "black"},
+						{Name: "끄기 (팀 대항전)", Value: "off"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "strength",
+					Description: "엔진의 강도 (1-5)",
+					Required:    false,
+					MinValue:    &minBotStrength,
+					MaxValue:    game.MaxBotStrength,
+				},
+			},
+		},
+		{
+			Name:                     "mode",
+			Description:              "투표로 수를 정하는 방식을 변경합니다.",
+			DefaultMemberPermissions: &adminPermission,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "mode",
+					Description: "결정 방식",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "다수결", Value: "majority"},
+						{Name: "결정권자", Value: "decider"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "move",
 			Description: "가능한 체스 수를 확인하거나 직접 수를 입력합니다.",
@@ -74,15 +291,76 @@ var (
 					Description: "이동할 수 (UCI 형식)",
 					Required:    false,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "with_reason",
+					Description: "투표와 함께 한 줄 이유를 남깁니다.",
+					Required:    false,
+				},
 			},
 		},
 	}
 	commandIDs = make(map[string]string)
 )
 
+// themeOptions lists the themes as choices and the board size, with a way back
+// to the server default for personal preferences. The piece sets are only
+// offered if sets besides the default one were loaded.
+func themeOptions(reset bool) []*discordgo.ApplicationCommandOption {
+	minBoardSize := float64(chess.MinBoardSize)
+	var themes, pieces []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range chess.ThemeNames() {
+		themes = append(themes, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+	for _, name := range chess.PieceSets() {
+		pieces = append(pieces, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+	}
+	if reset {
+		themes = append(themes, &discordgo.ApplicationCommandOptionChoice{Name: "서버 기본값", Value: handlers.ThemeReset})
+	}
+	// Discord allows at most 25 choices.
+	if len(pieces) > 25 {
+		pieces = pieces[:25]
+	}
+
+	options := []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "theme",
+			Description: "보드 색상 테마",
+			Required:    false,
+			Choices:     themes,
+		},
+	}
+	if len(pieces) > 1 {
+		options = append(options, &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "pieces",
+			Description: "기물 세트 (기본값: 테마의 기물 세트)",
+			Required:    false,
+			Choices:     pieces,
+		})
+	}
+	return append(options, &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "size",
+		Description: fmt.Sprintf("보드 이미지의 크기 (%d~%dpx, 기본값: %dpx)", chess.MinBoardSize, chess.MaxBoardSize, chess.DefaultBoardSize),
+		Required:    false,
+		MinValue:    &minBoardSize,
+		MaxValue:    chess.MaxBoardSize,
+	})
+}
+
 func (bot *Bot) addSlashCommands(s *discordgo.Session) {
 	fmt.Println("Adding commands...")
 	for _, v := range commands {
+		// The piece sets are known once the images are loaded.
+		switch v.Name {
+		case "theme":
+			v.Options = themeOptions(true)
+		case "servertheme":
+			v.Options = themeOptions(false)
+		}
 		cmd, err := s.ApplicationCommandCreate(s.State.User.ID, "", v)
 		if err != nil {
 			fmt.Printf("Cannot create command %v: %v\n", v.Name, err)
@@ -103,3 +381,82 @@ func (bot *Bot) CleanUp(s *discordgo.Session) {
 	}
 	fmt.Println("Commands removed!")
 }
+
+// AnnounceTurn posts the result of a turn to the team channels.
+// resultMsg is the message returned by Game.Next, which is empty unless the game is over.
+func (bot *Bot) AnnounceTurn(resultMsg string) {
+	if bot.session == nil {
+		return
+	}
+
+	message := resultMsg
+	if message == "" {
+		message = bot.game.TurnMessage()
+	}
+
+	for _, channelID := range bot.teamChannels() {
+		if _, err := bot.session.ChannelMessageSend(channelID, message); err != nil {
+			fmt.Printf("Cannot announce turn in %s: %v\n", channelID, err)
+		}
+	}
+
+	if bot.game.IsGameOver() {
+		go bot.postReplay(bot.game.ChessGame.Clone())
+		go bot.postReport(bot.game.ChessGame.Clone(), bot.game.Votes())
+	}
+}
+
+// teamChannels returns the distinct announcement channels of both teams.
+func (bot *Bot) teamChannels() []string {
+	var channels []string
+	for _, id := range []string{bot.game.WhiteChannelID, bot.game.BlackChannelID} {
+		if id == "" || (len(channels) > 0 && channels[0] == id) {
+			continue
+		}
+		channels = append(channels, id)
+	}
+	return channels
+}
+
+// BeforeDeadline sends the captain of the team to move a private summary of the tally.
+func (bot *Bot) BeforeDeadline() {
+	if bot.session == nil || bot.game.IsGameOver() {
+		return
+	}
+
+	captain := bot.game.TurnCaptain()
+	if captain == "" {
+		return
+	}
+
+	channel, err := bot.session.UserChannelCreate(captain)
+	if err != nil {
+		fmt.Printf("Cannot open DM with captain %s: %v\n", captain, err)
+		return
+	}
+
+	message := fmt.Sprintf("주장님, 턴 마감까지 %d분 남았습니다.\n\n%s", int(time.Until(bot.game.NextTime).Minutes()), bot.game.GetTopNVotes(5))
+	if bot.game.Vetoed == "" {
+		message += "\n\n이번 턴에 아직 거부권을 사용하지 않았습니다. `/veto`로 한 개의 수를 제외할 수 있습니다."
+	}
+	if _, err := bot.session.ChannelMessageSend(channel.ID, message); err != nil {
+		fmt.Printf("Cannot send captain summary: %v\n", err)
+	}
+}
+
+// StartRunoff ends the proposal phase and announces the runoff candidates to the team to move.
+func (bot *Bot) StartRunoff() {
+	if !bot.game.StartRunoff() || bot.session == nil {
+		return
+	}
+
+	channelID := bot.game.TurnChannelID()
+	if channelID == "" {
+		return
+	}
+
+	message := fmt.Sprintf("제안 단계가 끝났습니다. 결선 투표 후보: **%s**\n`/move`로 후보 중 하나에 투표하세요.", strings.Join(bot.game.CandidateSANs(), "**, **"))
+	if _, err := bot.session.ChannelMessageSend(channelID, message); err != nil {
+		fmt.Printf("Cannot announce runoff in %s: %v\n", channelID, err)
+	}
+}
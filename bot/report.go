@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"hunsuChess/analysis"
+	"hunsuChess/chess"
+	"hunsuChess/engine"
+	"hunsuChess/opening"
+
+	"github.com/bwmarrin/discordgo"
+	notnilchess "github.com/notnil/chess"
+)
+
+// How long the post-game review may take before it is given up.
+const reportTimeout = 30 * time.Minute
+
+// postReport reviews the finished game and posts the result to the team channels.
+func (bot *Bot) postReport(g *notnilchess.Game, votes []map[string]string) {
+	analyzer := engine.Analyzer(bot.game.Searcher)
+	limits := engine.Limits{Depth: 4, MoveTime: 3 * time.Second}
+	if bot.game.Engine != nil {
+		analyzer = bot.game.Engine
+		limits = engine.Limits{Depth: 14, MoveTime: time.Second}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+
+	report, err := analysis.Review(ctx, analyzer, limits, g, votes)
+	if err != nil {
+		fmt.Printf("Cannot review game: %v\n", err)
+		return
+	}
+
+	tags := map[string]string{
+		"Event":  "hunsuChess",
+		"Site":   "Discord",
+		"Date":   time.Now().UTC().Format("2006.01.02"),
+		"White":  "백팀",
+		"Black":  "흑팀",
+		"Result": string(report.Outcome),
+	}
+	o := opening.Find(g)
+	if o != nil {
+		tags["ECO"] = o.Code
+		tags["Opening"] = o.Name
+	}
+	pgn := report.PGN(tags, bot.voterNames(votes))
+
+	embed := reportEmbed(report)
+	if o != nil {
+		embed.Description = "오프닝: " + o.String()
+	}
+	for _, channelID := range bot.teamChannels() {
+		// Readers are consumed by the upload, so every channel gets fresh ones.
+		message := &discordgo.MessageSend{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{
+				{
+					Name:        "eval.png",
+					ContentType: "image/png",
+					Reader:      chess.EvalGraphImage(report.Evals()),
+				},
+				{
+					Name:        "game.pgn",
+					ContentType: "application/x-chess-pgn",
+					Reader:      strings.NewReader(pgn),
+				},
+			},
+		}
+		if _, err := bot.session.ChannelMessageSendComplex(channelID, message); err != nil {
+			fmt.Printf("Cannot post report in %s: %v\n", channelID, err)
+		}
+	}
+}
+
+func reportEmbed(report *analysis.Report) *discordgo.MessageEmbed {
+	summary := func(color notnilchess.Color, accuracy float64) string {
+		return fmt.Sprintf("정확도 **%.1f%%**\n부정확 %d · 실수 %d · 대실수 %d",
+			accuracy,
+			report.Count(color, analysis.Inaccuracy),
+			report.Count(color, analysis.Mistake),
+			report.Count(color, analysis.Blunder))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "게임 분석",
+		Color: 0x9b59b6,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "백팀", Value: summary(notnilchess.White, report.WhiteAccuracy), Inline: true},
+			{Name: "흑팀", Value: summary(notnilchess.Black, report.BlackAccuracy), Inline: true},
+		},
+		Image: &discordgo.MessageEmbedImage{
+			URL: "attachment://eval.png",
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "수마다의 평가와 최선의 수는 첨부된 PGN에 있습니다.",
+		},
+	}
+
+	// The worst moves of the game, with what the engine preferred.
+	plies := append([]analysis.Ply(nil), report.Plies...)
+	sort.SliceStable(plies, func(i, j int) bool {
+		return plies[i].Accuracy < plies[j].Accuracy
+	})
+	var worst []string
+	for _, ply := range plies {
+		if len(worst) == 5 || ply.Class < analysis.Mistake {
+			break
+		}
+		dots := "."
+		if ply.Color == notnilchess.Black {
+			dots = "..."
+		}
+		worst = append(worst, fmt.Sprintf("%d%s %s (%s) → 최선: %s, 지지 %d명",
+			(ply.Number+1)/2, dots, ply.SAN, ply.Class.Name(), ply.BestSAN, len(ply.Backers)))
+	}
+	if len(worst) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "결정적인 수",
+			Value: strings.Join(worst, "\n"),
+		})
+	}
+
+	// Voters who backed the engine's preferred move most often.
+	backed := map[string]int{}
+	for _, ply := range report.Plies {
+		for _, id := range ply.Backers {
+			backed[id]++
+		}
+	}
+	voters := make([]string, 0, len(backed))
+	for id := range backed {
+		voters = append(voters, id)
+	}
+	sort.Slice(voters, func(i, j int) bool {
+		if backed[voters[i]] != backed[voters[j]] {
+			return backed[voters[i]] > backed[voters[j]]
+		}
+		return voters[i] < voters[j]
+	})
+	var top []string
+	for i := 0; i < len(voters) && i < 5; i++ {
+		top = append(top, fmt.Sprintf("<@%s> %d회", voters[i], backed[voters[i]]))
+	}
+	if len(top) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "엔진 추천 수를 가장 많이 지지한 투표자",
+			Value: strings.Join(top, "\n"),
+		})
+	}
+
+	return embed
+}
+
+// voterNames looks up the display names of everyone who voted in the game.
+func (bot *Bot) voterNames(votes []map[string]string) map[string]string {
+	names := map[string]string{}
+	for _, turn := range votes {
+		for id := range turn {
+			if _, ok := names[id]; ok {
+				continue
+			}
+			names[id] = id
+			if user, err := bot.session.User(id); err == nil {
+				names[id] = user.Username
+			}
+		}
+	}
+	return names
+}
+
+// postReplay posts the finished game as an animated GIF to the team channels.
+func (bot *Bot) postReplay(g *notnilchess.Game) {
+	replay, err := chess.Replay(g, chess.ReplayOptions{})
+	if err != nil {
+		fmt.Printf("Cannot draw replay: %v\n", err)
+		return
+	}
+	data, err := io.ReadAll(replay)
+	if err != nil {
+		fmt.Printf("Cannot draw replay: %v\n", err)
+		return
+	}
+
+	for _, channelID := range bot.teamChannels() {
+		message := &discordgo.MessageSend{
+			Content: "게임 다시 보기",
+			Files: []*discordgo.File{
+				{
+					Name:        "replay.gif",
+					ContentType: "image/gif",
+					Reader:      bytes.NewReader(data),
+				},
+			},
+		}
+		if _, err := bot.session.ChannelMessageSendComplex(channelID, message); err != nil {
+			fmt.Printf("Cannot post replay in %s: %v\n", channelID, err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"github.com/notnil/chess"
+)
+
+// Vote arrows are coloured by rank: the leading move, the runner-up and the rest.
+var voteColors = []color.NRGBA{
+	{40, 170, 70, 255},
+	{40, 110, 220, 255},
+	{140, 140, 140, 255},
+}
+
+var (
+	tagText   = color.NRGBA{255, 255, 255, 255}
+	markerTag = color.NRGBA{50, 50, 50, 220}
+)
+
+// votedMove is a move with the number of votes for it. Moves with as many
+// votes share a rank, 0 being the most voted.
+type votedMove struct {
+	move  string
+	count int
+	rank  int
+}
+
+// tallyVotes counts the votes per move, most voted first.
+func tallyVotes(votes []string) []votedMove {
+	counts := make(map[string]int)
+	for _, vote := range votes {
+		counts[vote]++
+	}
+
+	tally := make([]votedMove, 0, len(counts))
+	for move, count := range counts {
+		tally = append(tally, votedMove{move: move, count: count})
+	}
+	sort.Slice(tally, func(i, j int) bool {
+		if tally[i].count != tally[j].count {
+			return tally[i].count > tally[j].count
+		}
+		return tally[i].move < tally[j].move
+	})
+
+	for i := range tally {
+		if i > 0 && tally[i].count == tally[i-1].count {
+			tally[i].rank = tally[i-1].rank
+		} else {
+			tally[i].rank = i
+		}
+	}
+	return tally
+}
+
+// addVoteArrows draws one arrow per voted move, wider and more opaque the
+// larger its share of the votes, with the number of votes at its head.
+func addVoteArrows(img image.Image, pos *chess.Position, votes []string, orientation chess.Color) image.Image {
+	if len(votes) == 0 {
+		return img
+	}
+	tally := tallyVotes(votes)
+	board := gg.NewContextForImage(img)
+
+	// The least voted go first so the leading arrows stay on top.
+	for i := len(tally) - 1; i >= 0; i-- {
+		vote := tally[i]
+		share := float64(vote.count) / float64(len(votes))
+		c := voteColors[min(vote.rank, len(voteColors)-1)]
+		c.A = uint8(110 + 130*share)
+
+		shaft := 3 + 5*share
+		drawArrow(board, vote.move, orientation, shaft, shaft+8, c)
+	}
+
+	for _, vote := range tally {
+		c := voteColors[min(vote.rank, len(voteColors)-1)]
+		square := float64(board.Width()) / 8
+		file, rank := GetPosition(vote.move[2:4], orientation)
+		drawTag(board, strconv.Itoa(vote.count), (file+0.5)*square, (rank+0.5)*square, c)
+		drawMarker(board, pos, vote.move, orientation)
+	}
+
+	return board.Image()
+}
+
+// moveMarker returns the mark of a castling or a promotion, or an empty string.
+func moveMarker(pos *chess.Position, move string) string {
+	if len(move) == 5 {
+		return "=" + strings.ToUpper(move[4:])
+	}
+	if pos == nil || len(move) != 4 {
+		return ""
+	}
+	from, to := move[:2], move[2:4]
+	if !isKing(pos, from) {
+		return ""
+	}
+	switch int(to[0]) - int(from[0]) {
+	case 2:
+		return "O-O"
+	case -2:
+		return "O-O-O"
+	}
+	return ""
+}
+
+func isKing(pos *chess.Position, square string) bool {
+	for sq, p := range pos.Board().SquareMap() {
+		if sq.String() == square {
+			return p.Type() == chess.King
+		}
+	}
+	return false
+}
+
+// drawMarker tags a castling or promotion near the square the move starts from.
+func drawMarker(board *gg.Context, pos *chess.Position, move string, orientation chess.Color) {
+	marker := moveMarker(pos, move)
+	if marker == "" {
+		return
+	}
+	square := float64(board.Width()) / 8
+	file, rank := GetPosition(move[:2], orientation)
+	drawTag(board, marker, (file+0.5)*square, (rank+0.8)*square, markerTag)
+}
+
+// drawTag draws a small rounded label centred on x, y.
+func drawTag(board *gg.Context, text string, x float64, y float64, fill color.Color) {
+	square := float64(board.Width()) / 8
+	face, release := fontFace(square * 10 / 45)
+	defer release()
+	board.SetFontFace(face)
+
+	w, h := board.MeasureString(text)
+	padding := square * 3 / 45
+	width := max(w+2*padding, h+padding)
+	height := h + padding
+	board.DrawRoundedRectangle(x-width/2, y-height/2, width, height, height/2)
+	board.SetColor(fill)
+	board.Fill()
+
+	board.SetColor(tagText)
+	board.DrawStringAnchored(text, x, y, 0.5, 0.35)
+}
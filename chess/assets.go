@@ -0,0 +1,142 @@
+package chess
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// The default board and pieces are built into the binary, so the bot runs
+// from any working directory. Extra piece sets live in images/pieces/<name>/
+// with the same 12 file names.
+//
+//go:embed images
+var defaultAssets embed.FS
+
+// Images of any square size are accepted. Larger ones are scaled down to the
+// largest size they are drawn at, smaller ones are scaled up when drawn.
+const (
+	maxBoardImageSize = MaxBoardSize
+	maxPieceImageSize = MaxBoardSize / 8
+)
+
+func init() {
+	images, err := fs.Sub(defaultAssets, "images")
+	if err != nil {
+		panic(err)
+	}
+	// The embedded files are known to be valid.
+	if errs := loadAssets(images); len(errs) != 0 {
+		panic(errors.Join(errs...))
+	}
+}
+
+// LoadAssets replaces the embedded images with those in dir, which has the
+// layout of the images directory. Every file is checked; a missing or broken
+// file keeps its embedded version and is reported in the returned error.
+func LoadAssets(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return errors.Join(loadAssets(os.DirFS(dir))...)
+}
+
+// loadAssets loads the board, the default piece set and the sets in pieces/,
+// keeping the current images for the files that fail.
+func loadAssets(fsys fs.FS) []error {
+	var errs []error
+
+	if img, err := loadImage(fsys, "board.png", maxBoardImageSize); err != nil {
+		errs = append(errs, err)
+	} else {
+		board = img
+	}
+
+	pieces, pieceErrs := loadPieceSet(fsys, ".")
+	errs = append(errs, pieceErrs...)
+	if len(pieces) != 0 {
+		if pieceSets[DefaultPieceSet] == nil {
+			pieceSets[DefaultPieceSet] = pieces
+		} else {
+			for char, img := range pieces {
+				pieceSets[DefaultPieceSet][char] = img
+			}
+		}
+	}
+
+	dirs, err := fs.ReadDir(fsys, "pieces")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		// An extra set is only usable if it is complete.
+		pieces, pieceErrs := loadPieceSet(fsys, path.Join("pieces", dir.Name()))
+		if len(pieceErrs) != 0 {
+			errs = append(errs, fmt.Errorf("piece set %s is skipped: %w", dir.Name(), errors.Join(pieceErrs...)))
+			continue
+		}
+		pieceSets[dir.Name()] = pieces
+	}
+	resetScaled()
+	return errs
+}
+
+// loadPieceSet loads the pieces in dir that are valid. The pieces of a set
+// must all have the size of the first one, the white king.
+func loadPieceSet(fsys fs.FS, dir string) (map[rune]image.Image, []error) {
+	pieces := make(map[rune]image.Image)
+	var errs []error
+	size := 0
+	for _, char := range "KQRBNPkqrbnp" {
+		name := path.Join(dir, piece_to_file_name[char]+".png")
+		img, err := loadImage(fsys, name, maxPieceImageSize)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if size == 0 {
+			size = img.Bounds().Dx()
+		} else if img.Bounds().Dx() != size {
+			errs = append(errs, fmt.Errorf("%s is %dx%d, unlike the other pieces of the set at %dx%d", name, img.Bounds().Dx(), img.Bounds().Dy(), size, size))
+			continue
+		}
+		pieces[char] = img
+	}
+	return pieces, errs
+}
+
+// loadImage decodes a square image, scaling it down to limit pixels if it is larger.
+func loadImage(fsys fs.FS, name string, limit int) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s is missing", name)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() != bounds.Dy() || bounds.Empty() {
+		return nil, fmt.Errorf("%s is %dx%d, expected a square image", name, bounds.Dx(), bounds.Dy())
+	}
+	if bounds.Dx() > limit {
+		img = scaleImage(img, limit)
+	}
+	return img, nil
+}
@@ -0,0 +1,70 @@
+package chess
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"testing/fstest"
+)
+
+func pngFile(t *testing.T, width, height int) *fstest.MapFile {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return &fstest.MapFile{Data: buf.Bytes()}
+}
+
+func TestLoadImage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"small.png":  pngFile(t, 30, 30),
+		"large.png":  pngFile(t, 2000, 2000),
+		"wide.png":   pngFile(t, 400, 360),
+		"broken.png": &fstest.MapFile{Data: []byte("not an image")},
+	}
+	tests := []struct {
+		name string
+		size int // 0 if the image is rejected
+	}{
+		{"small.png", 30},
+		{"large.png", maxBoardImageSize},
+		{"wide.png", 0},
+		{"broken.png", 0},
+		{"missing.png", 0},
+	}
+	for _, test := range tests {
+		img, err := loadImage(fsys, test.name, maxBoardImageSize)
+		switch {
+		case test.size == 0 && err == nil:
+			t.Errorf("%s accepted", test.name)
+		case test.size != 0 && err != nil:
+			t.Errorf("%s rejected: %v", test.name, err)
+		case test.size != 0 && (img.Bounds().Dx() != test.size || img.Bounds().Dy() != test.size):
+			t.Errorf("%s loaded at %v, want %dx%d", test.name, img.Bounds(), test.size, test.size)
+		}
+	}
+}
+
+func TestLoadPieceSet(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for _, char := range "KQRBNPkqrbnp" {
+		fsys["pieces/even/"+piece_to_file_name[char]+".png"] = pngFile(t, 60, 60)
+		fsys["pieces/odd/"+piece_to_file_name[char]+".png"] = pngFile(t, 60, 60)
+	}
+	fsys["pieces/odd/bN.png"] = pngFile(t, 90, 90)
+	fsys["pieces/odd/wQ.png"] = pngFile(t, 60, 50)
+
+	pieces, errs := loadPieceSet(fsys, "pieces/even")
+	if len(errs) != 0 || len(pieces) != 12 {
+		t.Errorf("loaded %d pieces with errors %v, want the full set", len(pieces), errs)
+	}
+	pieces, errs = loadPieceSet(fsys, "pieces/odd")
+	if len(errs) != 2 || len(pieces) != 10 {
+		t.Errorf("loaded %d pieces with errors %v, want the two mis-sized ones rejected", len(pieces), errs)
+	}
+	if _, ok := pieces['n']; ok {
+		t.Error("piece of another size than the set loaded")
+	}
+}
@@ -0,0 +1,30 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+)
+
+var captionBackground = color.NRGBA{38, 36, 33, 255}
+var captionText = color.NRGBA{235, 235, 235, 255}
+
+// addCaption puts a strip with the caption under the image, e.g. the side to
+// move and the time left, or the move just played. The strip is as high as
+// the material panels of a board of the given size.
+func addCaption(img image.Image, caption string, size int) image.Image {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	strip := size / 12
+	dc := gg.NewContext(width, height+strip)
+	dc.SetColor(captionBackground)
+	dc.Clear()
+	dc.DrawImage(img, 0, 0)
+
+	face, release := fontFace(float64(strip) * 0.6)
+	defer release()
+	dc.SetFontFace(face)
+	dc.SetColor(captionText)
+	dc.DrawStringAnchored(caption, float64(width)/2, float64(height)+float64(strip)/2, 0.5, 0.35)
+	return dc.Image()
+}
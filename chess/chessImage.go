@@ -8,35 +8,22 @@ import (
 	"image/png"
 	"io"
 	"math"
-	"os"
 	"strconv"
-	"strings"
+	"unicode"
+
+	"hunsuChess/engine"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 
 	"github.com/fogleman/gg"
+	"github.com/notnil/chess"
 )
 
-var light = color.RGBA{235, 209, 166, 255}
-var dark = color.RGBA{165, 117, 81, 255}
-
 var board image.Image
-var pieces = map[rune]image.Image{
-	'b': nil,
-	'k': nil,
-	'n': nil,
-	'p': nil,
-	'q': nil,
-	'r': nil,
-	'B': nil,
-	'K': nil,
-	'N': nil,
-	'P': nil,
-	'Q': nil,
-	'R': nil,
-}
+
+// Piece images by set name and FEN letter.
+var pieceSets = map[string]map[rune]image.Image{}
 
 var piece_to_file_name = map[rune]string{
 	'b': "bB",
@@ -53,155 +40,259 @@ var piece_to_file_name = map[rune]string{
 	'R': "wR",
 }
 
-func init() {
-	file_board, err := os.Open("images/board.png")
-	if err != nil {
-		panic(err)
-	}
-	defer file_board.Close()
-
-	data, _, err := image.Decode(file_board)
-	if err != nil {
-		panic(err)
-	}
-	board = data
-
-	for piece_type := range pieces {
-		file, err := os.Open("images/" + piece_to_file_name[piece_type] + ".png")
-		if err != nil {
-			panic(err)
-		}
-		defer file.Close()
-
-		data, _, err := image.Decode(file)
-		if err != nil {
-			panic(err)
-		}
-		pieces[piece_type] = data
-	}
-}
-
-func addLabel(img *image.NRGBA, x, y int, label string, c color.Color) {
+func addLabel(img *image.NRGBA, face font.Face, x, y int, label string, c color.Color) {
 	point := fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)}
 
 	d := &font.Drawer{
 		Dst:  img,
 		Src:  image.NewUniform(c),
-		Face: basicfont.Face7x13,
+		Face: face,
 		Dot:  point,
 	}
 	d.DrawString(label)
 }
 
-func ChessImage(fen string, arrows []string, team string) io.Reader {
-	width, height := 360, 360
+// Highlight marks a square of the board, e.g. "e4", with a translucent colour.
+type Highlight struct {
+	Square string
+	Color  color.Color
+}
+
+// RenderOptions describes how a position is drawn. The zero value draws the
+// board from white's side in the default theme and size.
+type RenderOptions struct {
+	Orientation  chess.Color   // side at the bottom of the board, white if NoColor
+	Highlights   []Highlight   // squares coloured under the pieces
+	LastMove     string        // UCI notation of the move just played, highlighted
+	PreviousMove string        // UCI notation of the move before it, in a second colour
+	Arrows       []string      // moves in UCI notation, e.g. "e2e4"
+	Votes        []string      // one move per vote, drawn weighted by their share
+	HideLabels   bool          // leaves out the file and rank labels
+	Material     bool          // adds the captured pieces and material balance above and below
+	Eval         *engine.Score // white's evaluation, drawn as a bar on the left if not nil
+	Caption      string        // banner under the board, e.g. "백 차례 · 3시간 남음"
+	Theme        *Theme        // colours and pieces, the default theme if nil
+	Size         int           // board size in pixels, the theme's size if 0
+}
+
+// Orientation returns the side at the bottom of the board for a team name.
+func Orientation(team string) chess.Color {
+	if team == "black" {
+		return chess.Black
+	}
+	return chess.White
+}
+
+// Render draws the position as a PNG image.
+func Render(pos *chess.Position, opts RenderOptions) io.Reader {
+	file := bytes.NewBuffer([]byte{})
+
+	png.Encode(file, RenderImage(pos, opts))
+
+	return file
+}
 
-	img := image.NewNRGBA(image.Rect(0, 0, width, height))
-	draw.Draw(img, img.Bounds(), board, image.Point{}, draw.Over)
+// RenderImage draws the position, for callers that encode it themselves.
+func RenderImage(pos *chess.Position, opts RenderOptions) image.Image {
+	theme := opts.Theme
+	if theme == nil {
+		theme = themes[DefaultTheme]
+	}
+	orientation := opts.Orientation
+	if orientation != chess.Black {
+		orientation = chess.White
+	}
+	size := opts.Size
+	if size == 0 {
+		size = theme.Size
+	}
+	size = BoardSize(size)
+	square := size / 8
+	pieces := scaledPieces(theme.Pieces, square)
+
+	highlights := moveHighlights(opts.PreviousMove, theme.Previous)
+	highlights = append(highlights, moveHighlights(opts.LastMove, theme.Highlight)...)
+	highlights = append(highlights, opts.Highlights...)
+	background := addHighlights(boardImage(theme, size), highlights, orientation)
+	if king, ok := checkedKing(pos); ok {
+		background = addCheckGlow(background, king.String(), orientation)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), background, image.Point{}, draw.Over)
 
 	// Set Pieces
-	datas := strings.Split(fen, " ")
-	x, y := 0, 0
-
-	for _, char := range datas[0] {
-		if char == '/' {
-			y++
-			x = 0
-		} else if char >= '1' && char <= '8' {
-			x += int(char - '0')
-		} else {
-			draw.Draw(img, image.Rect(x*45, y*45, x*45+45, y*45+45), pieces[char], image.Point{}, draw.Over)
-			x++
-		}
+	for sq, p := range pos.Board().SquareMap() {
+		file, rank := GetPosition(sq.String(), orientation)
+		x, y := int(file)*square, int(rank)*square
+		draw.Draw(img, image.Rect(x, y, x+square, y+square), pieces[pieceChar(p)], image.Point{}, draw.Over)
+	}
+
+	if !opts.HideLabels {
+		addLabels(img, theme, orientation)
 	}
 
-	// File and Rank
+	arrows := addVoteArrows(img, pos, opts.Votes, orientation)
+	result := addArrows(arrows, pos, opts.Arrows, orientation, theme.Arrow)
+	if opts.Eval != nil {
+		result = addEvalBar(result, *opts.Eval, orientation)
+	}
+	if opts.Material {
+		result = addMaterialPanels(result, pos, theme, orientation, size)
+	}
+	if opts.Caption != "" {
+		result = addCaption(result, opts.Caption, size)
+	}
+	return result
+}
+
+// pieceChar returns the FEN letter of the piece.
+func pieceChar(p chess.Piece) rune {
+	char := rune(p.Type().String()[0])
+	if p.Color() == chess.White {
+		char = unicode.ToUpper(char)
+	}
+	return char
+}
+
+// addLabels writes the files and ranks along the edges, placed as on the
+// 45px squares of the original board.
+func addLabels(img *image.NRGBA, theme *Theme, orientation chess.Color) {
+	size := img.Bounds().Dx()
+	square := size / 8
+	face, release := fontFace(float64(square) * 12 / 45)
+	defer release()
+	at := func(v int) int { return v * square / 45 }
+
 	for i := 1; i <= 8; i++ {
-		var c color.RGBA
+		c := theme.Light
 		if i%2 == 0 {
-			c = dark
-		} else {
-			c = light
+			c = theme.Dark
 		}
 
-		if team == "black" {
-			addLabel(img, 2, (9-i)*45-33, strconv.Itoa(9-i), c)
-			addLabel(img, i*45-8, 358, string(rune(9-i+'a'-1)), c)
+		if orientation == chess.Black {
+			addLabel(img, face, at(2), (9-i)*square-at(33), strconv.Itoa(9-i), c)
+			addLabel(img, face, i*square-at(9), size-at(3), string(rune(9-i+'a'-1)), c)
 		} else {
-			addLabel(img, 2, (9-i)*45-33, strconv.Itoa(i), c)
-			addLabel(img, i*45-8, 358, string(rune(i+'a'-1)), c)
+			addLabel(img, face, at(2), (9-i)*square-at(33), strconv.Itoa(i), c)
+			addLabel(img, face, i*square-at(9), size-at(3), string(rune(i+'a'-1)), c)
 		}
 	}
+}
 
-	file := bytes.NewBuffer([]byte{})
-
-	png.Encode(file, AddArrowsInBoard(img, arrows, team))
-
-	return file
+// boardImage returns the empty board of the theme at the given size.
+func boardImage(theme *Theme, size int) image.Image {
+	if theme.Board {
+		return scaledBoard(size)
+	}
+	square := size / 8
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for rank := 0; rank < 8; rank++ {
+		for file := 0; file < 8; file++ {
+			c := theme.Light
+			if (rank+file)%2 == 1 {
+				c = theme.Dark
+			}
+			draw.Draw(img, image.Rect(file*square, rank*square, file*square+square, rank*square+square), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+	}
+	return img
 }
 
-func GetPosition(position string, team string) (float64, float64) {
+// GetPosition returns the column and row of the square, e.g. "e4", counted
+// from the top left of the board as seen from the orientation.
+func GetPosition(position string, orientation chess.Color) (float64, float64) {
 	file := float64(position[0] - 'a')
 	rank := float64(7 - (position[1] - '1'))
 
-	if team == "black" {
+	if orientation == chess.Black {
 		file = 7 - file
 		rank = 7 - rank
 	}
 	return file, rank
 }
 
-func AddArrowsInBoard(img image.Image, arrows []string, team string) image.Image {
+func addArrows(img image.Image, pos *chess.Position, arrows []string, orientation chess.Color, arrowColor color.Color) image.Image {
 	board := gg.NewContextForImage(img)
 
 	for _, arrow := range arrows {
-		pre, post := arrow[:2], arrow[2:]
-		preFile, preRank := GetPosition(pre, team)
-		postFile, postRank := GetPosition(post, team)
+		drawArrow(board, arrow, orientation, 6, 14, arrowColor)
+	}
+	for _, arrow := range arrows {
+		drawMarker(board, pos, arrow, orientation)
+	}
 
-		preLineX := preFile*45 + 22.5
-		preLineY := preRank*45 + 22.5
+	return board.Image()
+}
 
-		postLineX := postFile*45 + 22.5
-		postLineY := postRank*45 + 22.5
+// drawArrow draws the move as an arrow whose shaft and head are shaft and head
+// pixels wide on each side, measured on the 45px squares of the original board.
+func drawArrow(board *gg.Context, arrow string, orientation chess.Color, shaft float64, head float64, arrowColor color.Color) {
+	square := float64(board.Width()) / 8
+	scale := square / 45
 
-		angle := math.Atan2(postLineY-preLineY, postLineX-preLineX)
+	pre, post := arrow[:2], arrow[2:]
+	preFile, preRank := GetPosition(pre, orientation)
+	postFile, postRank := GetPosition(post, orientation)
 
-		triAngleX := []float64{postLineX}
-		triAngleY := []float64{postLineY}
+	preLineX := (preFile + 0.5) * square
+	preLineY := (preRank + 0.5) * square
 
-		preLineX += math.Cos(angle) * 18.
-		preLineY += math.Sin(angle) * 18.
+	postLineX := (postFile + 0.5) * square
+	postLineY := (postRank + 0.5) * square
 
-		postLineX -= math.Cos(angle) * 18.
-		postLineY -= math.Sin(angle) * 18.
+	angle := math.Atan2(postLineY-preLineY, postLineX-preLineX)
 
-		triAngleX = append(triAngleX, postLineX+math.Cos(angle+math.Pi/2)*14.)
-		triAngleY = append(triAngleY, postLineY+math.Sin(angle+math.Pi/2)*14.)
+	triAngleX := []float64{postLineX}
+	triAngleY := []float64{postLineY}
 
-		triAngleX = append(triAngleX, postLineX+math.Cos(angle+math.Pi/2)*6.)
-		triAngleY = append(triAngleY, postLineY+math.Sin(angle+math.Pi/2)*6.)
+	preLineX += math.Cos(angle) * 18. * scale
+	preLineY += math.Sin(angle) * 18. * scale
 
-		triAngleX = append(triAngleX, preLineX+math.Cos(angle+math.Pi/2)*6.)
-		triAngleY = append(triAngleY, preLineY+math.Sin(angle+math.Pi/2)*6.)
+	postLineX -= math.Cos(angle) * 18. * scale
+	postLineY -= math.Sin(angle) * 18. * scale
 
-		triAngleX = append(triAngleX, preLineX+math.Cos(angle-math.Pi/2)*6.)
-		triAngleY = append(triAngleY, preLineY+math.Sin(angle-math.Pi/2)*6.)
+	triAngleX = append(triAngleX, postLineX+math.Cos(angle+math.Pi/2)*head*scale)
+	triAngleY = append(triAngleY, postLineY+math.Sin(angle+math.Pi/2)*head*scale)
 
-		triAngleX = append(triAngleX, postLineX+math.Cos(angle-math.Pi/2)*6.)
-		triAngleY = append(triAngleY, postLineY+math.Sin(angle-math.Pi/2)*6.)
+	triAngleX = append(triAngleX, postLineX+math.Cos(angle+math.Pi/2)*shaft*scale)
+	triAngleY = append(triAngleY, postLineY+math.Sin(angle+math.Pi/2)*shaft*scale)
 
-		triAngleX = append(triAngleX, postLineX+math.Cos(angle-math.Pi/2)*14.)
-		triAngleY = append(triAngleY, postLineY+math.Sin(angle-math.Pi/2)*14.)
+	triAngleX = append(triAngleX, preLineX+math.Cos(angle+math.Pi/2)*shaft*scale)
+	triAngleY = append(triAngleY, preLineY+math.Sin(angle+math.Pi/2)*shaft*scale)
 
-		triAngleX = append(triAngleX, triAngleX[0])
-		triAngleY = append(triAngleY, triAngleY[0])
+	triAngleX = append(triAngleX, preLineX+math.Cos(angle-math.Pi/2)*shaft*scale)
+	triAngleY = append(triAngleY, preLineY+math.Sin(angle-math.Pi/2)*shaft*scale)
 
-		board.SetRGBA(1, 1, 0, 0.8)
-		board.MoveTo(triAngleX[0], triAngleY[0])
-		for i := 0; i < len(triAngleX)-1; i++ {
-			board.LineTo(triAngleX[i+1], triAngleY[i+1])
-		}
+	triAngleX = append(triAngleX, postLineX+math.Cos(angle-math.Pi/2)*shaft*scale)
+	triAngleY = append(triAngleY, postLineY+math.Sin(angle-math.Pi/2)*shaft*scale)
+
+	triAngleX = append(triAngleX, postLineX+math.Cos(angle-math.Pi/2)*head*scale)
+	triAngleY = append(triAngleY, postLineY+math.Sin(angle-math.Pi/2)*head*scale)
+
+	triAngleX = append(triAngleX, triAngleX[0])
+	triAngleY = append(triAngleY, triAngleY[0])
+
+	board.SetColor(arrowColor)
+	board.MoveTo(triAngleX[0], triAngleY[0])
+	for i := 0; i < len(triAngleX)-1; i++ {
+		board.LineTo(triAngleX[i+1], triAngleY[i+1])
+	}
+	board.Fill()
+}
+
+func addHighlights(img image.Image, highlights []Highlight, orientation chess.Color) image.Image {
+	if len(highlights) == 0 {
+		return img
+	}
+	board := gg.NewContextForImage(img)
+	square := float64(img.Bounds().Dx()) / 8
+
+	for _, highlight := range highlights {
+		file, rank := GetPosition(highlight.Square, orientation)
+
+		board.DrawRectangle(file*square, rank*square, square, square)
+		board.SetColor(highlight.Color)
 		board.Fill()
 	}
 
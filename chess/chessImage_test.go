@@ -0,0 +1,29 @@
+package chess
+
+import (
+	"testing"
+
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+func TestRenderLayout(t *testing.T) {
+	pos := chess.NewGame().Position()
+	for _, size := range []int{MinBoardSize, DefaultBoardSize, MaxBoardSize} {
+		plain := RenderImage(pos, RenderOptions{Size: size, Material: true, Caption: "백 차례"})
+		withBar := RenderImage(pos, RenderOptions{Size: size, Material: true, Caption: "백 차례", Eval: &engine.Score{CP: 50}})
+
+		// Two material panels and the caption, each a twelfth of the board.
+		want := size + 3*(size/12)
+		if h := plain.Bounds().Dy(); h != want {
+			t.Errorf("size %d: image is %d high, want %d", size, h, want)
+		}
+		if h := withBar.Bounds().Dy(); h != want {
+			t.Errorf("size %d: image with the evaluation bar is %d high, want %d", size, h, want)
+		}
+		if withBar.Bounds().Dx() <= plain.Bounds().Dx() {
+			t.Errorf("size %d: the evaluation bar does not widen the image", size)
+		}
+	}
+}
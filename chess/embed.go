@@ -8,6 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"hunsuChess/endgame"
+	"hunsuChess/opening"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/notnil/chess"
 )
@@ -22,15 +25,20 @@ const (
 	PrefixMoveSelect = "move_select_"
 	PrefixMoveVote   = "move_vote_"
 	PrefixMoveCancel = "move_cancel_"
+	PrefixMoveReason = "move_reason_"
+	PrefixReasonLike = "reason_like_"
+
+	// CustomID of the text input in the reason modal
+	ReasonInputID = "reason"
 )
 
 // Generates the initial, paginated embed listing available moves as buttons.
-func CreateInitialMoveEmbed(g *chess.Game, userID string, team string, ephemeral bool) (*discordgo.MessageSend, error) {
-	return createMoveListPage(g, 0, userID, team, ephemeral)
+func CreateInitialMoveEmbed(g *chess.Game, userID string, team string, ephemeral bool, theme *Theme) (*discordgo.MessageSend, error) {
+	return createMoveListPage(g, 0, userID, team, ephemeral, theme)
 }
 
 // Generates an embed that shows a preview of a board state after a specific move.
-func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team string) (*discordgo.MessageEdit, error) {
+func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team string, theme *Theme) (*discordgo.MessageEdit, error) {
 	// Create a copy of the game to apply the move without changing the main game state.
 	gameCopy := g.Clone()
 	m, _ := chess.UCINotation{}.Decode(g.Position(), moveStr)
@@ -39,19 +47,14 @@ func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team s
 		return nil, fmt.Errorf("invalid move for preview: %w", err)
 	}
 
-	fen := gameCopy.FEN()
-	if team == "black" {
-		parts := strings.Split(fen, " ")
-		runes := []rune(parts[0])
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		parts[0] = string(runes)
-		fen = strings.Join(parts, " ")
-	}
-
 	// Generate image for the new state.
-	imageReader := ChessImage(fen, []string{}, team) // No votes shown in preview
+	// No votes shown in preview, only the previewed move
+	imageReader := Render(gameCopy.Position(), RenderOptions{
+		Orientation: Orientation(team),
+		LastMove:    moveStr,
+		Material:    true,
+		Theme:       theme,
+	})
 	imageName := fmt.Sprintf("chess-%d.png", time.Now().UnixNano())
 
 	san := chess.AlgebraicNotation{}.Encode(g.Position(), m)
@@ -64,6 +67,12 @@ func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team s
 			URL: "attachment://" + imageName,
 		},
 	}
+	if summary := endgame.MoveSummary(g.Position(), m); summary != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Endgame Table",
+			Value: summary,
+		})
+	}
 
 	// Create "Vote" and "Back" buttons
 	components := []discordgo.MessageComponent{
@@ -74,6 +83,11 @@ func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team s
 					Style:    discordgo.SuccessButton,
 					CustomID: fmt.Sprintf("%s%s;%s", PrefixMoveVote, moveStr, userID),
 				},
+				discordgo.Button{
+					Label:    "Vote with Reason",
+					Style:    discordgo.PrimaryButton,
+					CustomID: fmt.Sprintf("%s%s;%s", PrefixMoveReason, moveStr, userID),
+				},
 				discordgo.Button{
 					Label:    "Back to List",
 					Style:    discordgo.SecondaryButton,
@@ -104,8 +118,36 @@ func CreateMovePreviewEmbed(g *chess.Game, moveStr string, userID string, team s
 	return msgEdit, nil
 }
 
+// Generates a modal asking for a one-line reason before voting for a move.
+func CreateReasonModal(g *chess.Game, moveStr string, userID string) *discordgo.InteractionResponseData {
+	title := moveStr
+	m, err := chess.UCINotation{}.Decode(g.Position(), moveStr)
+	if err == nil {
+		title = chess.AlgebraicNotation{}.Encode(g.Position(), m)
+	}
+
+	return &discordgo.InteractionResponseData{
+		CustomID: fmt.Sprintf("%s%s;%s", PrefixMoveReason, moveStr, userID),
+		Title:    fmt.Sprintf("Vote for %s", title),
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    ReasonInputID,
+						Label:       "Why this move? (optional)",
+						Style:       discordgo.TextInputShort,
+						Placeholder: "e.g. Controls the center and opens the bishop",
+						Required:    false,
+						MaxLength:   100,
+					},
+				},
+			},
+		},
+	}
+}
+
 // Helper function that creates a specific page of the move list.
-func createMoveListPage(g *chess.Game, page int, userID string, team string, ephemeral bool) (*discordgo.MessageSend, error) {
+func createMoveListPage(g *chess.Game, page int, userID string, team string, ephemeral bool, theme *Theme) (*discordgo.MessageSend, error) {
 	validMoves := g.ValidMoves()
 	if len(validMoves) == 0 {
 		return &discordgo.MessageSend{Content: "No valid moves available."}, nil
@@ -120,19 +162,14 @@ func createMoveListPage(g *chess.Game, page int, userID string, team string, eph
 	}
 	pagedMoves := validMoves[start:end]
 
-	fen := g.FEN()
-	if team == "black" {
-		parts := strings.Split(fen, " ")
-		runes := []rune(parts[0])
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		parts[0] = string(runes)
-		fen = strings.Join(parts, " ")
-	}
-
 	// Generate the current board image with votes
-	imageReader := ChessImage(fen, getVoteStrings(g), team)
+	imageReader := Render(g.Position(), RenderOptions{
+		Orientation: Orientation(team),
+		Votes:       getVoteStrings(g),
+		LastMove:    lastMove(g),
+		Material:    true,
+		Theme:       theme,
+	})
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "Available Moves",
@@ -141,6 +178,7 @@ func createMoveListPage(g *chess.Game, page int, userID string, team string, eph
 		Image: &discordgo.MessageEmbedImage{
 			URL: "attachment://chess.png",
 		},
+		Fields: openingFields(g),
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: fmt.Sprintf("Page %d of %d", page+1, numPages),
 		},
@@ -189,6 +227,55 @@ func createMoveListPage(g *chess.Game, page int, userID string, team string, eph
 	return msg, nil
 }
 
+// Generates a compact embed for the runoff, with one vote button per candidate move.
+func CreateRunoffEmbed(g *chess.Game, candidates []string, userID string, team string, theme *Theme) (*discordgo.MessageSend, error) {
+	// Candidates are drawn as arrows so they can be compared at a glance.
+	imageReader := Render(g.Position(), RenderOptions{
+		Orientation: Orientation(team),
+		Arrows:      candidates,
+		LastMove:    lastMove(g),
+		Theme:       theme,
+	})
+
+	var sans []string
+	row := discordgo.ActionsRow{}
+	for _, candidate := range candidates {
+		m, err := chess.UCINotation{}.Decode(g.Position(), candidate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid runoff candidate: %w", err)
+		}
+		san := chess.AlgebraicNotation{}.Encode(g.Position(), m)
+		sans = append(sans, san)
+		row.Components = append(row.Components, discordgo.Button{
+			Label:    san,
+			Style:    discordgo.SuccessButton,
+			CustomID: fmt.Sprintf("%s%s;%s", PrefixMoveVote, candidate, userID),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Runoff",
+		Description: fmt.Sprintf("Only the top nominations can be voted for: **%s**", strings.Join(sans, "**, **")),
+		Color:       0xffa500, // Orange for runoff
+		Image: &discordgo.MessageEmbedImage{
+			URL: "attachment://chess.png",
+		},
+	}
+
+	return &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{row},
+		Files: []*discordgo.File{
+			{
+				Name:        "chess.png",
+				ContentType: "image/png",
+				Reader:      imageReader,
+			},
+		},
+		Flags: discordgo.MessageFlagsEphemeral,
+	}, nil
+}
+
 // This function is not exported, it's a helper for createMoveListPage
 func buildMoveButtonRows(g *chess.Game, moves []*chess.Move, userID string) []discordgo.MessageComponent {
 	var rows []discordgo.MessageComponent
@@ -225,7 +312,7 @@ func getVoteStrings(g *chess.Game) []string {
 }
 
 // CreatePaginationMessageEdit is used to update the message for page navigation
-func CreatePaginationMessageEdit(g *chess.Game, page int, votes []string, userID string, team string) (*discordgo.MessageEdit, error) {
+func CreatePaginationMessageEdit(g *chess.Game, page int, votes []string, userID string, team string, theme *Theme) (*discordgo.MessageEdit, error) {
 	validMoves := g.ValidMoves()
 	if len(validMoves) == 0 {
 		return &discordgo.MessageEdit{Content: strPtr("No valid moves available.")}, nil
@@ -239,18 +326,13 @@ func CreatePaginationMessageEdit(g *chess.Game, page int, votes []string, userID
 	}
 	pagedMoves := validMoves[start:end]
 
-	fen := g.FEN()
-	if team == "black" {
-		parts := strings.Split(fen, " ")
-		runes := []rune(parts[0])
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		parts[0] = string(runes)
-		fen = strings.Join(parts, " ")
-	}
-
-	imageReader := ChessImage(fen, votes, team)
+	imageReader := Render(g.Position(), RenderOptions{
+		Orientation: Orientation(team),
+		Votes:       votes,
+		LastMove:    lastMove(g),
+		Material:    true,
+		Theme:       theme,
+	})
 	imageName := fmt.Sprintf("chess-%d.png", time.Now().UnixNano())
 
 	embed := &discordgo.MessageEmbed{
@@ -260,6 +342,7 @@ func CreatePaginationMessageEdit(g *chess.Game, page int, votes []string, userID
 		Image: &discordgo.MessageEmbedImage{
 			URL: "attachment://" + imageName,
 		},
+		Fields: openingFields(g),
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: fmt.Sprintf("Page %d of %d", page+1, numPages),
 		},
@@ -308,6 +391,23 @@ func CreatePaginationMessageEdit(g *chess.Game, page int, votes []string, userID
 	return msgEdit, nil
 }
 
+// openingFields shows the opening reached so far, if it is known.
+func openingFields(g *chess.Game) []*discordgo.MessageEmbedField {
+	o := opening.Find(g)
+	if o == nil {
+		return nil
+	}
+	return []*discordgo.MessageEmbedField{
+		{Name: "Opening", Value: o.String()},
+	}
+}
+
 func strPtr(s string) *string {
 	return &s
 }
+
+// lastMove returns the move that led to the current position, if any.
+func lastMove(g *chess.Game) string {
+	last, _ := LastMoves(g)
+	return last
+}
@@ -0,0 +1,84 @@
+package chess
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"hunsuChess/engine"
+
+	"github.com/fogleman/gg"
+	"github.com/notnil/chess"
+)
+
+var evalWhite = color.NRGBA{235, 235, 235, 255}
+var evalBlack = color.NRGBA{50, 48, 45, 255}
+
+// whiteShare returns the part of the bar that is white's, squashed like the
+// evaluation graph so small advantages remain visible next to decided ones.
+func whiteShare(score engine.Score) float64 {
+	switch {
+	case score.Won, score.Mate > 0:
+		return 1
+	case score.Mated, score.Mate < 0:
+		return 0
+	}
+	return 1 / (1 + math.Exp(-0.7*float64(score.CP)/100))
+}
+
+// evalText writes the score for the bar, without the sign: the side is shown
+// by where it is written.
+func evalText(score engine.Score) string {
+	if score.Won || score.Mated {
+		return "#"
+	}
+	if score.Mate != 0 {
+		return fmt.Sprintf("M%d", max(score.Mate, -score.Mate))
+	}
+	return fmt.Sprintf("%.1f", math.Abs(float64(score.CP))/100)
+}
+
+// addEvalBar puts a vertical bar of the evaluation, from white's point of
+// view, on the left of the board. The viewing side's share grows from the bottom.
+func addEvalBar(board image.Image, score engine.Score, orientation chess.Color) image.Image {
+	size := board.Bounds().Dy()
+	width := size / 16
+	dc := gg.NewContext(width+board.Bounds().Dx(), size)
+	dc.DrawImage(board, width, 0)
+
+	share := whiteShare(score)
+	bottom, top := evalWhite, evalBlack
+	if orientation == chess.Black {
+		share = 1 - share
+		bottom, top = evalBlack, evalWhite
+	}
+	split := float64(size) * (1 - share)
+
+	dc.SetColor(top)
+	dc.DrawRectangle(0, 0, float64(width), split)
+	dc.Fill()
+	dc.SetColor(bottom)
+	dc.DrawRectangle(0, split, float64(width), float64(size)-split)
+	dc.Fill()
+
+	face, release := fontFace(float64(width) * 0.45)
+	defer release()
+	dc.SetFontFace(face)
+
+	// The score is written at the end of the side that is better, in the
+	// colour of the other side to stand out.
+	ahead := score.Value() > 0
+	if orientation == chess.Black {
+		ahead = score.Value() < 0
+	}
+	x := float64(width) / 2
+	if ahead {
+		dc.SetColor(top)
+		dc.DrawStringAnchored(evalText(score), x, float64(size)-float64(width)/2, 0.5, 0.35)
+	} else {
+		dc.SetColor(bottom)
+		dc.DrawStringAnchored(evalText(score), x, float64(width)/2, 0.5, 0.35)
+	}
+	return dc.Image()
+}
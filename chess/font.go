@@ -0,0 +1,170 @@
+package chess
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// hangulTTF is a subset of Noto Sans CJK KR with the common Hangul syllables,
+// so Korean captions can be drawn; Latin text is left to Go Regular. Other
+// scripts and rare syllables need a font given to LoadFont. See fonts/OFL.txt.
+//
+//go:embed fonts/NotoSansCJKkr-Hangul-Bold.ttf
+var hangulTTF []byte
+
+// labelFonts are tried in order for every character drawn on the board. A
+// font given to LoadFont goes first.
+var labelFonts = []*opentype.Font{mustParseFont(goregular.TTF), mustParseFont(hangulTTF)}
+
+func mustParseFont(data []byte) *opentype.Font {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		// The fonts are built in, so this does not happen.
+		panic(err)
+	}
+	return f
+}
+
+// LoadFont uses the TrueType or OpenType font at path, or the first font of
+// a collection, for the labels and captions. Characters it lacks are still
+// drawn with the built-in fonts.
+func LoadFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	f, err := collection.Font(0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 12, DPI: 72}); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	labelFonts = append([]*opentype.Font{f}, labelFonts...)
+	resetScaled()
+	return nil
+}
+
+// fontFace returns a face of the label fonts of the given pixel size and the
+// function giving it back once the drawing is done. Faces are not safe for
+// concurrent use, so a face is lent to one image at a time and then kept in
+// the scaled cache for the next image of that size.
+func fontFace(size float64) (font.Face, func()) {
+	scaled.Lock()
+	free := scaled.faces[size]
+	if n := len(free); n != 0 {
+		face := free[n-1]
+		scaled.faces[size] = free[:n-1]
+		scaled.Unlock()
+		return face, func() { releaseFace(size, face) }
+	}
+	scaled.Unlock()
+
+	face := newFace(size)
+	if face == nil {
+		return basicfont.Face7x13, func() {}
+	}
+	return face, func() { releaseFace(size, face) }
+}
+
+// releaseFace keeps the face for the next image of that size, unless the
+// label fonts changed while it was lent.
+func releaseFace(size float64, face *fallbackFace) {
+	scaled.Lock()
+	defer scaled.Unlock()
+	if len(face.fonts) != len(labelFonts) || face.fonts[0] != labelFonts[0] {
+		face.Close()
+		return
+	}
+	scaled.faces[size] = append(scaled.faces[size], face)
+}
+
+// newFace returns a face of the label fonts, or nil if one cannot be used at that size.
+func newFace(size float64) *fallbackFace {
+	face := &fallbackFace{fonts: labelFonts}
+	for _, f := range labelFonts {
+		ff, err := opentype.NewFace(f, &opentype.FaceOptions{
+			Size:    size,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			face.Close()
+			return nil
+		}
+		face.faces = append(face.faces, ff)
+	}
+	return face
+}
+
+// fallbackFace draws every character with the first of its fonts that has it.
+type fallbackFace struct {
+	fonts []*opentype.Font
+	faces []font.Face
+	buf   sfnt.Buffer
+}
+
+// pick returns the face for r, the first one if no font has it.
+func (f *fallbackFace) pick(r rune) font.Face {
+	for i, fnt := range f.fonts {
+		if index, err := fnt.GlyphIndex(&f.buf, r); err == nil && index != 0 {
+			return f.faces[i]
+		}
+	}
+	return f.faces[0]
+}
+
+func (f *fallbackFace) Close() error {
+	var errs []error
+	for _, face := range f.faces {
+		errs = append(errs, face.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.pick(r).GlyphAdvance(r)
+}
+
+// Kern only applies between characters of the same font.
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.pick(r0)
+	if face != f.pick(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+// Metrics are those of the first font, with room for the tallest of them.
+func (f *fallbackFace) Metrics() font.Metrics {
+	m := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		other := face.Metrics()
+		m.Height = max(m.Height, other.Height)
+		m.Ascent = max(m.Ascent, other.Ascent)
+		m.Descent = max(m.Descent, other.Descent)
+	}
+	return m
+}
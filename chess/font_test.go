@@ -0,0 +1,66 @@
+package chess
+
+import (
+	"image"
+	"sync"
+	"testing"
+
+	"github.com/notnil/chess"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestFontFaceReuse(t *testing.T) {
+	resetScaled()
+	face, release := fontFace(12)
+	release()
+	again, release := fontFace(12)
+	if again != face {
+		t.Error("a released face is not reused for the same size")
+	}
+	other, releaseOther := fontFace(20)
+	if other == face {
+		t.Error("a face is shared by two sizes")
+	}
+	// The face is lent, so the next image of that size gets another one.
+	if lent, releaseLent := fontFace(12); lent == again {
+		t.Error("a lent face is given out twice")
+	} else {
+		releaseLent()
+	}
+	release()
+	releaseOther()
+}
+
+func TestDrawConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addCaption(image.NewNRGBA(image.Rect(0, 0, 240, 240)), "백 차례 1. e4", 240)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBuiltInFontsCoverCaptions(t *testing.T) {
+	captions := []string{
+		"시작", "12. Nf3", "12... O-O-O · ", "백 차례 · 흑 차례 · 1분 미만 남음 · 3시간 12분",
+		outcomeCaption(chess.WhiteWon), outcomeCaption(chess.BlackWon), outcomeCaption(chess.Draw),
+		teamNames[chess.White], teamNames[chess.Black], "+39", "#-3",
+	}
+	var buf sfnt.Buffer
+	for _, caption := range captions {
+		for _, r := range caption {
+			found := false
+			for _, f := range labelFonts {
+				if index, err := f.GlyphIndex(&buf, r); err == nil && index != 0 {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("no built-in font has %q of %q", r, caption)
+			}
+		}
+	}
+}
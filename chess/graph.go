@@ -0,0 +1,56 @@
+package chess
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+var graphBackground = color.RGBA{38, 36, 33, 255}
+var graphWhite = color.RGBA{235, 235, 235, 255}
+var graphMidline = color.RGBA{120, 120, 120, 255}
+
+// EvalGraphImage draws the evaluation of every ply, in pawns from white's
+// point of view, as an area chart. White's advantage fills from the bottom.
+func EvalGraphImage(evals []float64) io.Reader {
+	width, height := 600.0, 200.0
+	dc := gg.NewContext(int(width), int(height))
+	dc.SetColor(graphBackground)
+	dc.Clear()
+
+	// Squash the evaluation so small advantages remain visible next to decided ones.
+	y := func(eval float64) float64 {
+		share := 1 / (1 + math.Exp(-0.7*eval))
+		return height - share*height
+	}
+	x := func(i int) float64 {
+		if len(evals) <= 1 {
+			return 0
+		}
+		return float64(i) * width / float64(len(evals)-1)
+	}
+
+	if len(evals) > 0 {
+		dc.MoveTo(0, height)
+		for i, eval := range evals {
+			dc.LineTo(x(i), y(eval))
+		}
+		dc.LineTo(width, height)
+		dc.ClosePath()
+		dc.SetColor(graphWhite)
+		dc.Fill()
+	}
+
+	dc.SetColor(graphMidline)
+	dc.SetLineWidth(1)
+	dc.DrawLine(0, height/2, width, height/2)
+	dc.Stroke()
+
+	file := bytes.NewBuffer([]byte{})
+	png.Encode(file, dc.Image())
+	return file
+}
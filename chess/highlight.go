@@ -0,0 +1,131 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+	"github.com/notnil/chess"
+)
+
+// checkColor is the glow around a king in check.
+var checkColor = color.NRGBA{230, 30, 30, 255}
+
+// LastMoves returns the last move of the game and the move before it in UCI
+// notation, or empty strings if there are none.
+func LastMoves(g *chess.Game) (last string, previous string) {
+	moves := g.Moves()
+	if len(moves) > 0 {
+		last = moves[len(moves)-1].String()
+	}
+	if len(moves) > 1 {
+		previous = moves[len(moves)-2].String()
+	}
+	return last, previous
+}
+
+// moveHighlights colours the squares a move left and reached.
+func moveHighlights(move string, c color.Color) []Highlight {
+	if len(move) < 4 {
+		return nil
+	}
+	return []Highlight{{Square: move[:2], Color: c}, {Square: move[2:4], Color: c}}
+}
+
+// checkedKing returns the square of the king of the side to move if it is in check.
+func checkedKing(pos *chess.Position) (chess.Square, bool) {
+	squares := pos.Board().SquareMap()
+	king := chess.NoSquare
+	for sq, p := range squares {
+		if p.Type() == chess.King && p.Color() == pos.Turn() {
+			king = sq
+		}
+	}
+	if king == chess.NoSquare {
+		return king, false
+	}
+	for sq, p := range squares {
+		if p.Color() != pos.Turn() && attacks(squares, sq, p, king) {
+			return king, true
+		}
+	}
+	return king, false
+}
+
+// attacks reports whether the piece p on from attacks the square to.
+func attacks(squares map[chess.Square]chess.Piece, from chess.Square, p chess.Piece, to chess.Square) bool {
+	df := int(to.File()) - int(from.File())
+	dr := int(to.Rank()) - int(from.Rank())
+	adf, adr := abs(df), abs(dr)
+
+	switch p.Type() {
+	case chess.Pawn:
+		forward := 1
+		if p.Color() == chess.Black {
+			forward = -1
+		}
+		return adf == 1 && dr == forward
+	case chess.Knight:
+		return adf*adr == 2
+	case chess.King:
+		return max(adf, adr) == 1
+	case chess.Rook:
+		if df != 0 && dr != 0 {
+			return false
+		}
+	case chess.Bishop:
+		if adf != adr {
+			return false
+		}
+	case chess.Queen:
+		if df != 0 && dr != 0 && adf != adr {
+			return false
+		}
+	}
+
+	// The line between the squares must be empty.
+	stepF, stepR := sign(df), sign(dr)
+	f, r := int(from.File())+stepF, int(from.Rank())+stepR
+	for f != int(to.File()) || r != int(to.Rank()) {
+		if _, ok := squares[chess.Square(r*8+f)]; ok {
+			return false
+		}
+		f, r = f+stepF, r+stepR
+	}
+	return true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// addCheckGlow draws a red glow fading out from the centre of the square.
+func addCheckGlow(img image.Image, square string, orientation chess.Color) image.Image {
+	board := gg.NewContextForImage(img)
+	size := float64(img.Bounds().Dx()) / 8
+	file, rank := GetPosition(square, orientation)
+	x, y := (file+0.5)*size, (rank+0.5)*size
+
+	glow := gg.NewRadialGradient(x, y, 0, x, y, size*0.7)
+	glow.AddColorStop(0, checkColor)
+	glow.AddColorStop(0.5, color.NRGBA{checkColor.R, checkColor.G, checkColor.B, 170})
+	glow.AddColorStop(1, color.NRGBA{checkColor.R, checkColor.G, checkColor.B, 0})
+	board.SetFillStyle(glow)
+	board.DrawRectangle(file*size, rank*size, size, size)
+	board.Fill()
+
+	return board.Image()
+}
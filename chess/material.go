@@ -0,0 +1,133 @@
+package chess
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/fogleman/gg"
+	"github.com/notnil/chess"
+)
+
+// The pieces of each side at the start, and their usual values in pawns.
+var (
+	startingPieces = map[chess.PieceType]int{
+		chess.Queen:  1,
+		chess.Rook:   2,
+		chess.Bishop: 2,
+		chess.Knight: 2,
+		chess.Pawn:   8,
+	}
+	materialValues = map[chess.PieceType]int{
+		chess.Queen:  9,
+		chess.Rook:   5,
+		chess.Bishop: 3,
+		chess.Knight: 3,
+		chess.Pawn:   1,
+	}
+	// Captured pieces are listed from the most valuable.
+	materialOrder = []chess.PieceType{chess.Queen, chess.Rook, chess.Bishop, chess.Knight, chess.Pawn}
+)
+
+// Material is what each side has taken from the other, compared to the
+// starting position.
+type Material struct {
+	White []chess.PieceType // black pieces captured by white
+	Black []chess.PieceType // white pieces captured by black
+	// Balance is white's material minus black's, in pawns.
+	Balance int
+}
+
+// MaterialOf compares the position to the starting material. A promoted
+// piece counts as the pawn it was, so it does not hide a capture.
+func MaterialOf(pos *chess.Position) Material {
+	counts := map[chess.Color]map[chess.PieceType]int{
+		chess.White: {},
+		chess.Black: {},
+	}
+	var m Material
+	for _, p := range pos.Board().SquareMap() {
+		if p.Type() == chess.King {
+			continue
+		}
+		counts[p.Color()][p.Type()]++
+		if p.Color() == chess.White {
+			m.Balance += materialValues[p.Type()]
+		} else {
+			m.Balance -= materialValues[p.Type()]
+		}
+	}
+
+	missing := func(c chess.Color) []chess.PieceType {
+		promoted := 0
+		for _, t := range materialOrder {
+			if t != chess.Pawn {
+				promoted += max(counts[c][t]-startingPieces[t], 0)
+			}
+		}
+		var captured []chess.PieceType
+		for _, t := range materialOrder {
+			n := startingPieces[t] - counts[c][t]
+			if t == chess.Pawn {
+				n -= promoted
+			}
+			for i := 0; i < n; i++ {
+				captured = append(captured, t)
+			}
+		}
+		return captured
+	}
+	m.White = missing(chess.Black)
+	m.Black = missing(chess.White)
+	return m
+}
+
+// teamNames are written on the material panels of each side.
+var teamNames = map[chess.Color]string{chess.White: "백팀", chess.Black: "흑팀"}
+
+// addMaterialPanels puts a strip above and below the board with the team on
+// that edge, the pieces it has captured and its lead in material if it has one.
+// The strips are as high as the caption of a board of the given size, even
+// when an evaluation bar made the image wider.
+func addMaterialPanels(board image.Image, pos *chess.Position, theme *Theme, orientation chess.Color, size int) image.Image {
+	width := board.Bounds().Dx()
+	panel := size / 12
+	dc := gg.NewContext(width, board.Bounds().Dy()+2*panel)
+	// Pieces of both colours stand out on the light squares.
+	dc.SetColor(theme.Light)
+	dc.Clear()
+	dc.DrawImage(board, 0, panel)
+
+	m := MaterialOf(pos)
+	pieces := scaledPieces(theme.Pieces, panel)
+	face, release := fontFace(float64(panel) * 0.6)
+	defer release()
+	dc.SetFontFace(face)
+
+	// Each side's strip is on its own edge of the board.
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		y := 0
+		if side == orientation {
+			y = panel + board.Bounds().Dy()
+		}
+		captured, lead := m.White, m.Balance
+		if side == chess.Black {
+			captured, lead = m.Black, -m.Balance
+		}
+
+		x := panel / 4
+		for i, t := range captured {
+			// Pieces of a kind overlap, kinds are spaced apart.
+			if i > 0 && captured[i-1] != t {
+				x += panel / 3
+			}
+			dc.DrawImage(pieces[pieceChar(chess.NewPiece(t, side.Other()))], x, y)
+			x += panel / 2
+		}
+		dc.SetColor(captionBackground)
+		if lead > 0 {
+			dc.DrawStringAnchored(fmt.Sprintf("+%d", lead), float64(x+panel*3/4), float64(y)+float64(panel)/2, 0, 0.35)
+		}
+		dc.DrawStringAnchored(teamNames[side], float64(width-panel/4), float64(y)+float64(panel)/2, 1, 0.35)
+	}
+	return dc.Image()
+}
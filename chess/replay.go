@@ -0,0 +1,189 @@
+package chess
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// DefaultReplayDelay is the time every position of a replay is shown.
+const DefaultReplayDelay = time.Second
+
+// ReplayOptions describes the plies and looks of a replay. Plies count from
+// 0 for the starting position; the zero value replays the whole game.
+type ReplayOptions struct {
+	From        int           // first ply shown
+	To          *int          // last ply shown, the last move of the game if nil
+	Delay       time.Duration // time each position is shown, DefaultReplayDelay if 0
+	Orientation chess.Color
+	Theme       *Theme
+	Size        int
+}
+
+// Replay draws the game as an animated GIF, one frame per position with the
+// move that led to it highlighted and written underneath.
+func Replay(g *chess.Game, opts ReplayOptions) (io.Reader, error) {
+	moves := g.Moves()
+	positions := g.Positions()
+	to := len(moves)
+	if opts.To != nil && *opts.To < to {
+		to = *opts.To
+	}
+	from := max(opts.From, 0)
+	if from > to {
+		return nil, fmt.Errorf("ply %d is after ply %d", from, to)
+	}
+	if len(moves) == 0 {
+		return nil, errors.New("no moves were played")
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = DefaultReplayDelay
+	}
+
+	frames := make([]image.Image, 0, to-from+1)
+	for ply := from; ply <= to; ply++ {
+		render := RenderOptions{Orientation: opts.Orientation, Theme: opts.Theme, Size: opts.Size, Caption: "시작"}
+		if ply > 0 {
+			m := moves[ply-1]
+			render.LastMove = m.String()
+			render.Caption = moveCaption(positions[ply-1], m)
+		}
+		if ply == len(moves) && g.Outcome() != chess.NoOutcome {
+			render.Caption += " · " + outcomeCaption(g.Outcome())
+		}
+		frames = append(frames, RenderImage(positions[ply], render))
+	}
+
+	theme := opts.Theme
+	if theme == nil {
+		theme = themes[DefaultTheme]
+	}
+	palette := framePalette(frames, theme)
+	anim := &gif.GIF{}
+	for i, frame := range frames {
+		anim.Image = append(anim.Image, toPaletted(frame, palette))
+		frameDelay := int(delay / (10 * time.Millisecond))
+		if i == len(frames)-1 {
+			// Linger on the last position before the replay starts over.
+			frameDelay *= 3
+		}
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	file := bytes.NewBuffer([]byte{})
+	if err := gif.EncodeAll(file, anim); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// moveCaption writes the move like "12. Nf3" or "12... Nc6".
+func moveCaption(pos *chess.Position, m *chess.Move) string {
+	san := chess.AlgebraicNotation{}.Encode(pos, m)
+	// The full move number is the last field of the FEN.
+	fields := strings.Fields(pos.String())
+	number := fields[len(fields)-1]
+	if pos.Turn() == chess.White {
+		return fmt.Sprintf("%s. %s", number, san)
+	}
+	return fmt.Sprintf("%s... %s", number, san)
+}
+
+// outcomeCaption writes the result of the game.
+func outcomeCaption(outcome chess.Outcome) string {
+	switch outcome {
+	case chess.WhiteWon:
+		return "백 승리 (1-0)"
+	case chess.BlackWon:
+		return "흑 승리 (0-1)"
+	}
+	return "무승부 (½-½)"
+}
+
+// framePalette picks the 256 colours that cover the frames best: the colours
+// of the theme's squares, then the most common colours of all frames after
+// merging those too close to tell apart, so the board squares and pieces keep
+// their exact colours.
+func framePalette(frames []image.Image, theme *Theme) color.Palette {
+	type bucket struct {
+		r, g, b, n int
+	}
+	buckets := make(map[uint16]*bucket)
+	for _, frame := range frames {
+		bounds := frame.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.NRGBAModel.Convert(frame.At(x, y)).(color.NRGBA)
+				key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+				b, ok := buckets[key]
+				if !ok {
+					b = &bucket{}
+					buckets[key] = b
+				}
+				b.r += int(c.R)
+				b.g += int(c.G)
+				b.b += int(c.B)
+				b.n++
+			}
+		}
+	}
+
+	sorted := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		sorted = append(sorted, b)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].n > sorted[j].n })
+
+	// Colours drawn on few pixels, such as a check, still get an exact match.
+	palette := color.Palette{
+		theme.Light, theme.Dark,
+		over(theme.Highlight, theme.Light), over(theme.Highlight, theme.Dark),
+		over(theme.Previous, theme.Light), over(theme.Previous, theme.Dark),
+		checkColor, captionBackground, captionText,
+	}
+	for _, b := range sorted {
+		if len(palette) == 256 {
+			break
+		}
+		palette = append(palette, color.NRGBA{uint8(b.r / b.n), uint8(b.g / b.n), uint8(b.b / b.n), 255})
+	}
+	return palette
+}
+
+// over returns the colour of top drawn over an opaque bottom.
+func over(top color.NRGBA, bottom color.RGBA) color.NRGBA {
+	blend := func(t, b uint8) uint8 {
+		return uint8((int(t)*int(top.A) + int(b)*(255-int(top.A)) + 127) / 255)
+	}
+	return color.NRGBA{blend(top.R, bottom.R), blend(top.G, bottom.G), blend(top.B, bottom.B), 255}
+}
+
+// toPaletted maps every pixel to the closest colour of the palette.
+func toPaletted(img image.Image, palette color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	dst := image.NewPaletted(bounds, palette)
+	// Boards have few distinct colours, so the closest ones are remembered.
+	closest := make(map[color.NRGBA]uint8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			index, ok := closest[c]
+			if !ok {
+				index = uint8(palette.Index(c))
+				closest[c] = index
+			}
+			dst.SetColorIndex(x, y, index)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,68 @@
+package chess
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestReplayRange(t *testing.T) {
+	g := chess.NewGame()
+	for _, move := range []string{"e4", "e5", "Nf3"} {
+		if err := g.MoveStr(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ply := func(n int) *int { return &n }
+	tests := []struct {
+		name   string
+		opts   ReplayOptions
+		frames int // 0 if the range is rejected
+	}{
+		{"whole game", ReplayOptions{}, 4},
+		{"start position only", ReplayOptions{To: ply(0)}, 1},
+		{"first move", ReplayOptions{From: 1, To: ply(1)}, 1},
+		{"past the end", ReplayOptions{From: 2, To: ply(10)}, 2},
+		{"from after to", ReplayOptions{From: 2, To: ply(1)}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.Size = MinBoardSize
+			replay, err := Replay(g, test.opts)
+			if test.frames == 0 {
+				if err == nil {
+					t.Error("range accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			anim, err := gif.DecodeAll(replay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(anim.Image) != test.frames {
+				t.Errorf("got %d frames, want %d", len(anim.Image), test.frames)
+			}
+		})
+	}
+}
+
+func TestFramePalette(t *testing.T) {
+	theme := themes["classic"]
+	frame := RenderImage(chess.NewGame().Position(), RenderOptions{Theme: theme, Size: MinBoardSize, LastMove: "e2e4"})
+	palette := framePalette([]image.Image{frame}, theme)
+	if len(palette) > 256 {
+		t.Fatalf("palette has %d colours", len(palette))
+	}
+	for _, c := range []color.Color{theme.Light, theme.Dark, over(theme.Highlight, theme.Light), over(theme.Highlight, theme.Dark)} {
+		r, g, b, a := c.RGBA()
+		if pr, pg, pb, pa := palette.Convert(c).RGBA(); pr != r || pg != g || pb != b || pa != a {
+			t.Errorf("palette lacks %v", c)
+		}
+	}
+}
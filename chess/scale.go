@@ -0,0 +1,105 @@
+package chess
+
+import (
+	"image"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Board sizes in pixels. Sizes are rounded down to a multiple of 8 so every
+// square has the same whole number of pixels.
+const (
+	DefaultBoardSize = 360
+	MinBoardSize     = 240
+	MaxBoardSize     = 1024
+)
+
+// BoardSize returns the size the board is drawn at for the requested size,
+// or the default size for 0.
+func BoardSize(size int) int {
+	switch {
+	case size == 0:
+		size = DefaultBoardSize
+	case size < MinBoardSize:
+		size = MinBoardSize
+	case size > MaxBoardSize:
+		size = MaxBoardSize
+	}
+	return size / 8 * 8
+}
+
+type scaleKey struct {
+	name string
+	size int
+}
+
+// Scaled images and font faces are cached, since the same few sizes are
+// drawn again and again. The faces of a size not lent out are kept in faces.
+var scaled = struct {
+	sync.Mutex
+	boards map[int]image.Image
+	pieces map[scaleKey]map[rune]image.Image
+	faces  map[float64][]*fallbackFace
+}{
+	boards: make(map[int]image.Image),
+	pieces: make(map[scaleKey]map[rune]image.Image),
+	faces:  make(map[float64][]*fallbackFace),
+}
+
+// resetScaled forgets the scaled images and faces after the assets or fonts
+// are replaced.
+func resetScaled() {
+	scaled.Lock()
+	defer scaled.Unlock()
+	scaled.boards = make(map[int]image.Image)
+	scaled.pieces = make(map[scaleKey]map[rune]image.Image)
+	for _, faces := range scaled.faces {
+		for _, face := range faces {
+			face.Close()
+		}
+	}
+	scaled.faces = make(map[float64][]*fallbackFace)
+}
+
+// scaleImage resamples img to a size×size image.
+func scaleImage(img image.Image, size int) image.Image {
+	if b := img.Bounds(); b.Dx() == size && b.Dy() == size {
+		return img
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Over, nil)
+	return dst
+}
+
+// scaledBoard returns board.png at the given size.
+func scaledBoard(size int) image.Image {
+	scaled.Lock()
+	defer scaled.Unlock()
+	img, ok := scaled.boards[size]
+	if !ok {
+		img = scaleImage(board, size)
+		scaled.boards[size] = img
+	}
+	return img
+}
+
+// scaledPieces returns the piece set with pieces of the given square size,
+// falling back to the default set if it is not loaded.
+func scaledPieces(set string, square int) map[rune]image.Image {
+	if pieceSets[set] == nil {
+		set = DefaultPieceSet
+	}
+	scaled.Lock()
+	defer scaled.Unlock()
+	key := scaleKey{set, square}
+	pieces, ok := scaled.pieces[key]
+	if !ok {
+		pieces = make(map[rune]image.Image)
+		for char, img := range pieceSets[set] {
+			pieces[char] = scaleImage(img, square)
+		}
+		scaled.pieces[key] = pieces
+	}
+	return pieces
+}
@@ -0,0 +1,237 @@
+package chess
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+)
+
+// DefaultTheme is used when neither the user nor the server chose a theme.
+const DefaultTheme = "classic"
+
+// DefaultPieceSet is the piece set in the images directory itself.
+// Other sets live in images/pieces/<name>/ with the same 12 file names.
+const DefaultPieceSet = "classic"
+
+// Theme decides the colours and pieces of the rendered board.
+type Theme struct {
+	Name      string
+	Light     color.RGBA  // light squares
+	Dark      color.RGBA  // dark squares
+	Highlight color.NRGBA // highlighted squares such as the last move
+	Previous  color.NRGBA // squares of the move before the last one
+	Arrow     color.NRGBA // vote and candidate arrows
+	Pieces    string      // piece set
+	Size      int         // board size in pixels, 0 for DefaultBoardSize
+	// Board is drawn with the image in board.png instead of painted squares.
+	Board bool
+}
+
+var themes = map[string]*Theme{
+	"classic": {
+		Name:      "classic",
+		Light:     color.RGBA{235, 209, 166, 255},
+		Dark:      color.RGBA{165, 117, 81, 255},
+		Highlight: color.NRGBA{205, 210, 106, 150},
+		Previous:  color.NRGBA{170, 162, 58, 110},
+		Arrow:     color.NRGBA{255, 255, 0, 204},
+		Pieces:    DefaultPieceSet,
+		Board:     true,
+	},
+	"green": {
+		Name:      "green",
+		Light:     color.RGBA{238, 238, 210, 255},
+		Dark:      color.RGBA{118, 150, 86, 255},
+		Highlight: color.NRGBA{246, 246, 105, 150},
+		Previous:  color.NRGBA{186, 202, 68, 110},
+		Arrow:     color.NRGBA{255, 170, 0, 204},
+		Pieces:    DefaultPieceSet,
+	},
+	"blue": {
+		Name:      "blue",
+		Light:     color.RGBA{222, 227, 230, 255},
+		Dark:      color.RGBA{140, 162, 173, 255},
+		Highlight: color.NRGBA{155, 199, 0, 150},
+		Previous:  color.NRGBA{105, 150, 0, 110},
+		Arrow:     color.NRGBA{255, 120, 0, 204},
+		Pieces:    DefaultPieceSet,
+	},
+	"gray": {
+		Name:      "gray",
+		Light:     color.RGBA{220, 220, 220, 255},
+		Dark:      color.RGBA{130, 130, 130, 255},
+		Highlight: color.NRGBA{120, 170, 230, 150},
+		Previous:  color.NRGBA{90, 130, 190, 110},
+		Arrow:     color.NRGBA{230, 60, 60, 204},
+		Pieces:    DefaultPieceSet,
+	},
+}
+
+// ThemeNames returns the names of the themes in alphabetical order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PieceSets returns the names of the loaded piece sets in alphabetical order.
+func PieceSets() []string {
+	names := make([]string, 0, len(pieceSets))
+	for name := range pieceSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasPieceSet reports whether the piece set is loaded.
+func HasPieceSet(name string) bool {
+	_, ok := pieceSets[name]
+	return ok
+}
+
+// LookupTheme returns the theme with the piece set replaced if pieces is not empty.
+func LookupTheme(name string, pieces string) (*Theme, bool) {
+	theme, ok := themes[name]
+	if !ok {
+		return nil, false
+	}
+	if pieces != "" && pieces != theme.Pieces && HasPieceSet(pieces) {
+		custom := *theme
+		custom.Pieces = pieces
+		return &custom, true
+	}
+	return theme, true
+}
+
+// ThemeChoice is a theme, piece set and board size picked by a user or a
+// server. Empty fields fall back to the next level: user, then server, then default.
+type ThemeChoice struct {
+	Theme  string
+	Pieces string
+	Size   int
+}
+
+// ThemeSettings remembers the server defaults and the users' preferences.
+// Settings loaded from a file are written back to it on every change.
+type ThemeSettings struct {
+	mu     sync.Mutex
+	path   string // file the settings are saved to, empty to keep them in memory
+	guilds map[string]ThemeChoice
+	users  map[string]ThemeChoice
+}
+
+// themeFile is the layout of the saved settings.
+type themeFile struct {
+	Guilds map[string]ThemeChoice
+	Users  map[string]ThemeChoice
+}
+
+func NewThemeSettings() *ThemeSettings {
+	return &ThemeSettings{
+		guilds: make(map[string]ThemeChoice),
+		users:  make(map[string]ThemeChoice),
+	}
+}
+
+// LoadThemeSettings reads the settings saved at path, which is created on
+// the first change if it does not exist yet.
+func LoadThemeSettings(path string) (*ThemeSettings, error) {
+	s := NewThemeSettings()
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		var file themeFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for id, choice := range file.Guilds {
+			s.guilds[id] = choice
+		}
+		for id, choice := range file.Users {
+			s.users[id] = choice
+		}
+	}
+	s.path = path
+	return s, nil
+}
+
+// SetGuildDefault sets the theme of the server, used by members without a preference.
+// The choice is kept even if it cannot be saved.
+func (s *ThemeSettings) SetGuildDefault(guildID string, choice ThemeChoice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guilds[guildID] = choice
+	return s.save()
+}
+
+// SetUser sets the user's preference. An empty choice goes back to the server default.
+// The choice is kept even if it cannot be saved.
+func (s *ThemeSettings) SetUser(userID string, choice ThemeChoice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if choice == (ThemeChoice{}) {
+		delete(s.users, userID)
+	} else {
+		s.users[userID] = choice
+	}
+	return s.save()
+}
+
+// save writes the settings to their file, if they have one. The caller holds s.mu.
+func (s *ThemeSettings) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(themeFile{Guilds: s.guilds, Users: s.users}, "", "\t")
+	if err != nil {
+		return err
+	}
+	// Replace the file at once, so a crash never leaves half of it.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Resolve returns the theme the user sees in the server.
+func (s *ThemeSettings) Resolve(guildID string, userID string) *Theme {
+	choice := ThemeChoice{Theme: DefaultTheme}
+	if s != nil {
+		s.mu.Lock()
+		for _, level := range []ThemeChoice{s.guilds[guildID], s.users[userID]} {
+			if level.Theme != "" {
+				choice.Theme = level.Theme
+			}
+			if level.Pieces != "" {
+				choice.Pieces = level.Pieces
+			}
+			if level.Size != 0 {
+				choice.Size = level.Size
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	theme, ok := LookupTheme(choice.Theme, choice.Pieces)
+	if !ok {
+		theme, _ = LookupTheme(DefaultTheme, choice.Pieces)
+	}
+	if choice.Size != 0 {
+		sized := *theme
+		sized.Size = BoardSize(choice.Size)
+		theme = &sized
+	}
+	return theme
+}
@@ -0,0 +1,52 @@
+package chess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThemeSettingsSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "themes.json")
+	settings, err := LoadThemeSettings(path)
+	if err != nil {
+		t.Fatalf("settings without a file: %v", err)
+	}
+	if err := settings.SetGuildDefault("guild", ThemeChoice{Theme: "green"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := settings.SetUser("alice", ThemeChoice{Theme: "blue", Size: 640}); err != nil {
+		t.Fatal(err)
+	}
+	settings.SetUser("bob", ThemeChoice{Theme: "gray"})
+	settings.SetUser("bob", ThemeChoice{}) // back to the server default
+
+	loaded, err := LoadThemeSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		user  string
+		theme string
+		size  int
+	}{
+		{"alice", "blue", 640},
+		{"bob", "green", 0},
+		{"carol", "green", 0},
+	}
+	for _, test := range tests {
+		if theme := loaded.Resolve("guild", test.user); theme.Name != test.theme || theme.Size != test.size {
+			t.Errorf("%s sees %s at %d after loading, want %s at %d", test.user, theme.Name, theme.Size, test.theme, test.size)
+		}
+	}
+	if theme := loaded.Resolve("other", "carol"); theme.Name != DefaultTheme {
+		t.Errorf("another server sees %s, want %s", theme.Name, DefaultTheme)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadThemeSettings(path); err == nil {
+		t.Error("broken settings file loaded")
+	}
+}
@@ -0,0 +1,229 @@
+// Package commentary describes chess moves in a sentence or two, for the turn
+// announcements. The sentences come from per-language templates.
+package commentary
+
+import (
+	"regexp"
+	"strings"
+
+	"hunsuChess/engine"
+	"hunsuChess/tactics"
+
+	"github.com/notnil/chess"
+)
+
+// Key names a template of a Locale.
+type Key string
+
+const (
+	Move       Key = "move"        // a quiet move: {color} {piece} {from} {to}
+	Develop    Key = "develop"     // a minor piece leaving its home square
+	Capture    Key = "capture"     // {captured} is the captured piece
+	EnPassant  Key = "en_passant"  // an en passant capture
+	CastleKing Key = "castle_king" // castling on the king's side
+	CastleLong Key = "castle_long" // castling on the queen's side
+	Promotion  Key = "promotion"   // {promo} is the new piece
+	PawnBreak  Key = "pawn_break"  // {target} is the enemy pawn now attacked
+	Trade      Key = "trade"       // a capture of a piece of the same value
+	Recapture  Key = "recapture"   // a capture on the square of the previous capture
+	Fork       Key = "fork"        // {targets} are the forked pieces
+	Pin        Key = "pin"         // {pinned} is the pinned piece
+	Check      Key = "check"
+	Checkmate  Key = "checkmate"
+
+	// On names a piece on a square: {piece} {square}.
+	On Key = "on"
+	// And joins two names of a list: {a} {b}.
+	And Key = "and"
+)
+
+// Locale holds the words and templates of one language. Templates refer to
+// values as {name}; {name|A/B} appends the Korean particle A after a final
+// consonant and B otherwise, e.g. {piece|이/가}.
+type Locale struct {
+	Colors    map[chess.Color]string
+	Pieces    map[chess.PieceType]string
+	Templates map[Key]string
+}
+
+var placeholder = regexp.MustCompile(`\{(\w+)(?:\|([^/}]*)/([^}]*))?\}`)
+
+func (l *Locale) format(key Key, values map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(l.Templates[key], func(match string) string {
+		parts := placeholder.FindStringSubmatch(match)
+		value := values[parts[1]]
+		if parts[2] == "" && parts[3] == "" {
+			return value
+		}
+		return value + particle(value, parts[2], parts[3])
+	})
+}
+
+// Comment describes the last move of the game, or returns an empty string if
+// no move was played.
+func Comment(g *chess.Game, l *Locale) string {
+	moves := g.Moves()
+	positions := g.Positions()
+	if len(moves) == 0 {
+		return ""
+	}
+	m := moves[len(moves)-1]
+	before := positions[len(positions)-2]
+	after := positions[len(positions)-1]
+
+	piece := before.Board().Piece(m.S1())
+	values := map[string]string{
+		"color": l.Colors[piece.Color()],
+		"piece": l.Pieces[piece.Type()],
+		"from":  m.S1().String(),
+		"to":    m.S2().String(),
+	}
+
+	var sentences []string
+	captured := before.Board().Piece(m.S2())
+	switch {
+	case m.HasTag(chess.KingSideCastle):
+		sentences = append(sentences, l.format(CastleKing, values))
+	case m.HasTag(chess.QueenSideCastle):
+		sentences = append(sentences, l.format(CastleLong, values))
+	case m.Promo() != chess.NoPieceType:
+		values["promo"] = l.Pieces[m.Promo()]
+		sentences = append(sentences, l.format(Promotion, values))
+	case m.HasTag(chess.EnPassant):
+		sentences = append(sentences, l.format(EnPassant, values))
+	case captured != chess.NoPiece:
+		values["captured"] = l.Pieces[captured.Type()]
+		sentences = append(sentences, l.format(Capture, values))
+		if len(moves) > 1 && moves[len(moves)-2].S2() == m.S2() && moves[len(moves)-2].HasTag(chess.Capture) {
+			sentences = append(sentences, l.format(Recapture, values))
+		} else if piece.Type() != chess.Pawn && engine.PieceValue(captured.Type()) == engine.PieceValue(piece.Type()) {
+			sentences = append(sentences, l.format(Trade, values))
+		}
+	case isDevelopment(piece, m):
+		sentences = append(sentences, l.format(Develop, values))
+	default:
+		sentences = append(sentences, l.format(Move, values))
+		if target, ok := pawnBreak(after, piece, m.S2()); ok {
+			values["target"] = l.on(after, target)
+			sentences = append(sentences, l.format(PawnBreak, values))
+		}
+	}
+
+	// A fork or pin by a piece that is simply lost is not worth mentioning.
+	hints := tactics.Analyze(after)
+	safe := true
+	for _, hint := range hints {
+		if (hint.Kind == tactics.Hanging || hint.Kind == tactics.Attacked) && hint.Square == m.S2() {
+			safe = false
+		}
+	}
+	if forked := forkTargets(after, m.S2()); safe && len(forked) >= 2 {
+		values["targets"] = l.list(after, forked)
+		sentences = append(sentences, l.format(Fork, values))
+	}
+	for _, hint := range hints {
+		if safe && hint.Kind == tactics.Pinned && hint.From[0] == m.S2() {
+			values["pinned"] = l.on(after, hint.Square)
+			sentences = append(sentences, l.format(Pin, values))
+			break
+		}
+	}
+
+	switch {
+	case after.Status() == chess.Checkmate:
+		sentences = append(sentences, l.format(Checkmate, values))
+	case m.HasTag(chess.Check):
+		sentences = append(sentences, l.format(Check, values))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// on names the piece on the square.
+func (l *Locale) on(pos *chess.Position, sq chess.Square) string {
+	return l.format(On, map[string]string{
+		"piece":  l.Pieces[pos.Board().Piece(sq).Type()],
+		"square": sq.String(),
+	})
+}
+
+func (l *Locale) list(pos *chess.Position, squares []chess.Square) string {
+	result := l.on(pos, squares[0])
+	for _, sq := range squares[1:] {
+		result = l.format(And, map[string]string{"a": result, "b": l.on(pos, sq)})
+	}
+	return result
+}
+
+// forkTargets returns the pieces, pawns aside, the piece on sq could win.
+func forkTargets(pos *chess.Position, sq chess.Square) []chess.Square {
+	var targets []chess.Square
+	for _, target := range tactics.Forked(pos, sq) {
+		if pos.Board().Piece(target).Type() != chess.Pawn {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// isDevelopment reports whether a knight or bishop leaves its home rank.
+func isDevelopment(p chess.Piece, m *chess.Move) bool {
+	if p.Type() != chess.Knight && p.Type() != chess.Bishop {
+		return false
+	}
+	home := chess.Rank1
+	if p.Color() == chess.Black {
+		home = chess.Rank8
+	}
+	return m.S1().Rank() == home && m.S2().Rank() != home
+}
+
+// pawnBreak returns an enemy pawn the pawn on sq now attacks, if the move was a pawn push.
+func pawnBreak(pos *chess.Position, p chess.Piece, sq chess.Square) (chess.Square, bool) {
+	if p.Type() != chess.Pawn {
+		return 0, false
+	}
+	forward := 1
+	if p.Color() == chess.Black {
+		forward = -1
+	}
+	rank := int(sq.Rank()) + forward
+	if rank < 0 || rank > 7 {
+		return 0, false
+	}
+	for _, df := range []int{-1, 1} {
+		file := int(sq.File()) + df
+		if file < 0 || file > 7 {
+			continue
+		}
+		target := chess.Square(rank*8 + file)
+		if q := pos.Board().Piece(target); q.Type() == chess.Pawn && q.Color() != p.Color() {
+			return target, true
+		}
+	}
+	return 0, false
+}
+
+// particle chooses between the Korean particles after word: withFinal after a
+// final consonant, otherwise without. 으로 is not used after ㄹ either.
+func particle(word string, withFinal string, without string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return without
+	}
+	last := runes[len(runes)-1]
+
+	var final, rieul bool
+	switch {
+	case last >= '가' && last <= '힣':
+		jong := (last - '가') % 28
+		final, rieul = jong != 0, jong == 8
+	case last >= '0' && last <= '9':
+		// Digits as read in Korean: 영, 일, 이, 삼, 사, 오, 육, 칠, 팔, 구.
+		final = strings.ContainsRune("013678", last)
+		rieul = strings.ContainsRune("178", last)
+	}
+	if final && !(rieul && withFinal == "으로") {
+		return withFinal
+	}
+	return without
+}
@@ -0,0 +1,77 @@
+package commentary
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestParticle(t *testing.T) {
+	tests := []struct {
+		word, withFinal, without string
+		want                     string
+	}{
+		{"룩", "이", "가", "이"},
+		{"나이트", "이", "가", "가"},
+		{"킹", "은", "는", "은"},
+		{"나이트", "은", "는", "는"},
+		{"비숍", "을", "를", "을"},
+		{"퀸", "을", "를", "을"},
+		{"폰", "을", "를", "을"},
+		{"흑", "이", "가", "이"},
+		{"d5의 퀸", "과", "와", "과"},
+		{"e4의 나이트", "과", "와", "와"},
+		// Squares end in digits read as 영, 일, 이, 삼, 사, 오, 육, 칠, 팔, 구.
+		{"e3", "으로", "로", "으로"},
+		{"e4", "으로", "로", "로"},
+		{"e6", "을", "를", "을"},
+		{"c2", "이", "가", "가"},
+		// 으로 becomes 로 after ㄹ, other particles do not.
+		{"e1", "으로", "로", "로"},
+		{"c7", "으로", "로", "로"},
+		{"h8", "이", "가", "이"},
+		{"", "이", "가", "가"},
+		{"queen", "이", "가", "가"},
+	}
+	for _, test := range tests {
+		if got := particle(test.word, test.withFinal, test.without); got != test.want {
+			t.Errorf("particle(%q, %q, %q) = %q, want %q", test.word, test.withFinal, test.without, got, test.want)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		fen     string
+		move    string
+		locale  *Locale
+		comment string
+	}{
+		{"quiet move", "", "e4", Korean, "백 폰이 e2에서 e4로 움직입니다."},
+		{"development", "", "Nf3", Korean, "백 나이트가 f3으로 전개합니다."},
+		{"fork", "r3k3/8/8/1N6/8/8/8/4K3 w - - 0 1", "Nc7+", Korean, "백 나이트가 b5에서 c7로 움직입니다. a8의 룩과 e8의 킹을 동시에 노리는 포크입니다! 체크!"},
+		{"fork in English", "r3k3/8/8/1N6/8/8/8/4K3 w - - 0 1", "Nc7+", English, "White moves the knight from b5 to c7. It forks the rook on a8 and the king on e8! Check!"},
+		{"pin", "4k3/8/2n5/8/8/8/8/4KB2 w - - 0 1", "Bb5", Korean, "백 비숍이 b5로 전개합니다. c6의 나이트를 핀으로 묶습니다."},
+		// The knight on c7 would simply be taken by the queen.
+		{"lost forking piece", "r3k3/3q4/8/1N6/8/8/8/4K3 w - - 0 1", "Nc7+", Korean, "백 나이트가 b5에서 c7로 움직입니다. 체크!"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := chess.NewGame()
+			if test.fen != "" {
+				fen, err := chess.FEN(test.fen)
+				if err != nil {
+					t.Fatal(err)
+				}
+				g = chess.NewGame(fen)
+			}
+			if err := g.MoveStr(test.move); err != nil {
+				t.Fatal(err)
+			}
+			if got := Comment(g, test.locale); got != test.comment {
+				t.Errorf("Comment = %q, want %q", got, test.comment)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package commentary
+
+import "github.com/notnil/chess"
+
+var Korean = &Locale{
+	Colors: map[chess.Color]string{
+		chess.White: "백",
+		chess.Black: "흑",
+	},
+	Pieces: map[chess.PieceType]string{
+		chess.King:   "킹",
+		chess.Queen:  "퀸",
+		chess.Rook:   "룩",
+		chess.Bishop: "비숍",
+		chess.Knight: "나이트",
+		chess.Pawn:   "폰",
+	},
+	Templates: map[Key]string{
+		Move:       "{color} {piece|이/가} {from}에서 {to|으로/로} 움직입니다.",
+		Develop:    "{color} {piece|이/가} {to|으로/로} 전개합니다.",
+		Capture:    "{color} {piece|이/가} {to}의 {captured|을/를} 잡습니다.",
+		EnPassant:  "{color} 폰이 앙파상으로 상대 폰을 잡고 {to|으로/로} 들어갑니다.",
+		CastleKing: "{color|이/가} 킹 쪽으로 캐슬링합니다.",
+		CastleLong: "{color|이/가} 퀸 쪽으로 캐슬링합니다.",
+		Promotion:  "{color} 폰이 {to}에서 {promo|으로/로} 승격합니다!",
+		PawnBreak:  "{target|을/를} 겨누는 폰 브레이크입니다.",
+		Trade:      "같은 가치의 기물을 맞바꾸자는 교환입니다.",
+		Recapture:  "{to}에서 되잡아 교환을 마무리합니다.",
+		Fork:       "{targets|을/를} 동시에 노리는 포크입니다!",
+		Pin:        "{pinned|을/를} 핀으로 묶습니다.",
+		Check:      "체크!",
+		Checkmate:  "체크메이트!",
+		On:         "{square}의 {piece}",
+		And:        "{a|과/와} {b}",
+	},
+}
+
+var English = &Locale{
+	Colors: map[chess.Color]string{
+		chess.White: "White",
+		chess.Black: "Black",
+	},
+	Pieces: map[chess.PieceType]string{
+		chess.King:   "king",
+		chess.Queen:  "queen",
+		chess.Rook:   "rook",
+		chess.Bishop: "bishop",
+		chess.Knight: "knight",
+		chess.Pawn:   "pawn",
+	},
+	Templates: map[Key]string{
+		Move:       "{color} moves the {piece} from {from} to {to}.",
+		Develop:    "{color} develops the {piece} to {to}.",
+		Capture:    "{color}'s {piece} takes the {captured} on {to}.",
+		EnPassant:  "{color} captures en passant on {to}.",
+		CastleKing: "{color} castles kingside.",
+		CastleLong: "{color} castles queenside.",
+		Promotion:  "{color} promotes a pawn to a {promo} on {to}!",
+		PawnBreak:  "A pawn break against {target}.",
+		Trade:      "Pieces of equal value are offered for a trade.",
+		Recapture:  "It recaptures on {to}, completing the trade.",
+		Fork:       "It forks {targets}!",
+		Pin:        "It pins {pinned}.",
+		Check:      "Check!",
+		Checkmate:  "Checkmate!",
+		On:         "the {piece} on {square}",
+		And:        "{a} and {b}",
+	},
+}
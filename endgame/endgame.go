@@ -0,0 +1,231 @@
+// Package endgame knows the exact result of endgames with few pieces: a king
+// with a queen, a rook, a pawn, or a bishop and a knight against a bare king.
+//
+// The tables are generated in memory by retrograde analysis the first time a
+// position needs them, so no tablebase files are required. The fifty-move
+// rule is not taken into account.
+package endgame
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/notnil/chess"
+)
+
+// Result is the outcome of a position with best play, for the side to move.
+type Result int
+
+const (
+	Draw Result = iota
+	Win
+	Loss
+)
+
+// Entry is the value of a position for the side to move.
+type Entry struct {
+	Result Result
+	Plies  int // plies until mate, 0 for a draw or when the side to move is mated
+}
+
+// MateIn returns the number of moves of the winning side until mate.
+func (e Entry) MateIn() int {
+	if e.Result == Win {
+		return (e.Plies + 1) / 2
+	}
+	return e.Plies / 2
+}
+
+type entry struct {
+	table    *table
+	once     sync.Once
+	mu       sync.Mutex
+	ready    bool
+	building bool // the generation was started in the background
+}
+
+var (
+	kqk  = newEntry(chess.Queen)
+	krk  = newEntry(chess.Rook)
+	kpk  = newEntry(chess.Pawn)
+	kbnk = newEntry(chess.Bishop, chess.Knight)
+
+	// Tables by the attacker's pieces, sorted by type.
+	tables = map[string]*entry{}
+)
+
+func init() {
+	kpk.table.promotions = []*table{kqk.table, krk.table}
+	for _, e := range []*entry{kqk, krk, kpk, kbnk} {
+		tables[signature(e.table.pieces)] = e
+	}
+}
+
+func newEntry(pieces ...chess.PieceType) *entry {
+	return &entry{table: &table{pieces: pieces, size: 1 << (12 + 6*len(pieces))}}
+}
+
+func signature(pieces []chess.PieceType) string {
+	sorted := append([]chess.PieceType(nil), pieces...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return fmt.Sprint(sorted)
+}
+
+// build generates the table and the tables it depends on, once.
+func (e *entry) build() {
+	e.once.Do(func() {
+		if e == kpk {
+			kqk.build()
+			krk.build()
+		}
+		e.table.build()
+		e.mu.Lock()
+		e.ready = true
+		e.mu.Unlock()
+	})
+}
+
+// available reports whether the table is ready, starting its generation in
+// the background if it is not.
+func (e *entry) available() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.ready && !e.building {
+		e.building = true
+		go e.build()
+	}
+	return e.ready
+}
+
+// Probe returns the exact value of the position. It returns false if the
+// material is not covered, or if its table is still being generated.
+// Positions without enough material to mate are draws.
+func Probe(pos *chess.Position) (Entry, bool) {
+	var white, black []chess.PieceType
+	var squares [2][]int
+	var kings [2]int
+	for sq, p := range pos.Board().SquareMap() {
+		side := 0
+		if p.Color() == chess.Black {
+			side = 1
+		}
+		switch {
+		case p.Type() == chess.King:
+			kings[side] = int(sq)
+			continue
+		case side == 0:
+			white = append(white, p.Type())
+		default:
+			black = append(black, p.Type())
+		}
+		squares[side] = append(squares[side], int(sq))
+	}
+
+	attacker, pieces := chess.White, white
+	switch {
+	case len(white) == 0 && len(black) == 0:
+		return Entry{}, true
+	case len(white) == 0:
+		attacker, pieces = chess.Black, black
+	case len(black) != 0:
+		return Entry{}, false
+	}
+	if len(pieces) == 1 && (pieces[0] == chess.Bishop || pieces[0] == chess.Knight) {
+		return Entry{}, true
+	}
+
+	e, ok := tables[signature(pieces)]
+	if !ok || !e.available() {
+		return Entry{}, false
+	}
+	t := e.table
+
+	// Mirror the board so the attacker plays up the board as white.
+	side, mirror := 0, 0
+	if attacker == chess.Black {
+		side, mirror = 1, 56
+	}
+	s := setup{ak: kings[side] ^ mirror, dk: kings[1-side] ^ mirror}
+	used := make([]bool, len(pieces))
+	for i, pt := range t.pieces {
+		for j, have := range pieces {
+			if have == pt && !used[j] {
+				used[j] = true
+				s.sq[i] = squares[side][j] ^ mirror
+				break
+			}
+		}
+	}
+	idx := t.index(s)
+
+	if pos.Turn() == attacker {
+		if plies := t.win[idx]; plies != unknown {
+			return Entry{Result: Win, Plies: int(plies)}, true
+		}
+	} else if plies := t.loss[idx]; plies != unknown {
+		return Entry{Result: Loss, Plies: int(plies)}, true
+	}
+	return Entry{}, true
+}
+
+// Summary describes the exact result of the position in Korean, or returns an
+// empty string if it is not known.
+func Summary(pos *chess.Position) string {
+	e, ok := Probe(pos)
+	if !ok {
+		return ""
+	}
+	winner := pos.Turn()
+	switch e.Result {
+	case Draw:
+		return "무승부 (최선의 수를 두면 어느 쪽도 이길 수 없습니다)"
+	case Loss:
+		if e.Plies == 0 {
+			return "체크메이트"
+		}
+		winner = winner.Other()
+	}
+	return fmt.Sprintf("%s이 %d수 만에 체크메이트할 수 있습니다", colorName(winner), e.MateIn())
+}
+
+// MoveSummary describes in Korean what the move changes to the exact result,
+// or returns an empty string if it is not known.
+func MoveSummary(pos *chess.Position, m *chess.Move) string {
+	before, ok := Probe(pos)
+	if !ok {
+		return ""
+	}
+	after, ok := Probe(pos.Update(m))
+	if !ok {
+		return ""
+	}
+
+	switch after.Result {
+	case Loss:
+		if after.Plies == 0 {
+			return "이 수는 체크메이트입니다."
+		}
+		mate := after.Plies/2 + 1
+		if before.Result == Win && before.MateIn() < mate {
+			return fmt.Sprintf("이 수도 이기지만 %d수 메이트로, 최선인 %d수 메이트보다 느립니다.", mate, before.MateIn())
+		}
+		return fmt.Sprintf("이 수는 %d수 메이트를 유지합니다.", mate)
+	case Win:
+		if before.Result == Loss && before.MateIn() == after.MateIn() {
+			return fmt.Sprintf("최선의 방어입니다. 상대는 여전히 %d수 만에 메이트할 수 있습니다.", after.MateIn())
+		}
+		return fmt.Sprintf("이 수를 두면 상대가 %d수 만에 메이트할 수 있습니다.", after.MateIn())
+	}
+	if before.Result == Win {
+		return "이 수는 승리를 놓치고 무승부가 됩니다."
+	}
+	return "이 수를 두어도 무승부입니다."
+}
+
+func colorName(c chess.Color) string {
+	if c == chess.White {
+		return "백"
+	}
+	return "흑"
+}
@@ -0,0 +1,104 @@
+package endgame
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func mustPosition(t *testing.T, fen string) *chess.Position {
+	t.Helper()
+	pos := &chess.Position{}
+	if err := pos.UnmarshalText([]byte(fen)); err != nil {
+		t.Fatalf("bad FEN %q: %v", fen, err)
+	}
+	return pos
+}
+
+func TestLongestMates(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry *entry
+		moves int
+	}{
+		{"KQK", kqk, 10},
+		{"KRK", krk, 16},
+		{"KBNK", kbnk, 33},
+	}
+	for _, test := range tests {
+		if test.entry == kbnk && testing.Short() {
+			continue // the largest table, it takes a while
+		}
+		test.entry.build()
+		longest := 0
+		for _, plies := range test.entry.table.win {
+			if plies != unknown && int(plies) > longest {
+				longest = int(plies)
+			}
+		}
+		if got := (Entry{Result: Win, Plies: longest}).MateIn(); got != test.moves {
+			t.Errorf("longest %s mate in %d, want %d", test.name, got, test.moves)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	kqk.build()
+	kpk.build()
+	tests := []struct {
+		name   string
+		fen    string
+		result Result
+		mateIn int
+	}{
+		{"queen, white to move", "4k3/8/8/8/8/8/8/3QK3 w - - 0 1", Win, -1},
+		{"queen, black to move", "4k3/8/8/8/8/8/8/3QK3 b - - 0 1", Loss, -1},
+		{"black queen, black to move", "3qk3/8/8/8/8/8/8/4K3 b - - 0 1", Win, -1},
+		{"black queen, white to move", "3qk3/8/8/8/8/8/8/4K3 w - - 0 1", Loss, -1},
+		{"mate in one", "4k3/8/4K3/8/8/8/8/7Q w - - 0 1", Win, 1},
+		{"checkmated", "4k3/4Q3/4K3/8/8/8/8/8 b - - 0 1", Loss, 0},
+		{"queen stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Draw, 0},
+		{"pawn stalemate", "k7/P7/K7/8/8/8/8/8 b - - 0 1", Draw, 0},
+		// With the pawn behind its king, the side to move loses the opposition.
+		{"opposition, white to move", "8/8/8/4k3/8/4K3/4P3/8 w - - 0 1", Draw, 0},
+		{"opposition, black to move", "8/8/8/4k3/8/4K3/4P3/8 b - - 0 1", Loss, -1},
+		{"king in front of the pawn", "4k3/8/4K3/4P3/8/8/8/8 w - - 0 1", Win, -1},
+		{"rook pawn", "k7/8/8/8/8/8/P7/K7 b - - 0 1", Draw, 0},
+		{"bare kings", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", Draw, 0},
+		{"lone bishop", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", Draw, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e, ok := Probe(mustPosition(t, test.fen))
+			if !ok {
+				t.Fatal("position not covered")
+			}
+			if e.Result != test.result || (test.mateIn >= 0 && e.MateIn() != test.mateIn) {
+				t.Errorf("Probe = %+v (mate in %d), want result %d mating in %d", e, e.MateIn(), test.result, test.mateIn)
+			}
+		})
+	}
+
+	if _, ok := Probe(mustPosition(t, "4k3/4r3/8/8/8/8/8/3QK3 w - - 0 1")); ok {
+		t.Error("material on both sides is covered")
+	}
+}
+
+func TestAvailableBuildsOnce(t *testing.T) {
+	e := newEntry(chess.Queen)
+	if e.available() {
+		t.Fatal("table ready before it was generated")
+	}
+	e.mu.Lock()
+	building := e.building
+	e.mu.Unlock()
+	if !building {
+		t.Fatal("probe did not start the generation")
+	}
+	e.available() // must not start a second generation
+
+	e.build() // waits for the background generation
+	if !e.available() {
+		t.Error("table not ready after its generation")
+	}
+}
@@ -0,0 +1,334 @@
+package endgame
+
+import (
+	"math/bits"
+
+	"github.com/notnil/chess"
+)
+
+// unknown marks positions without a forced mate, which are draws once a table is complete.
+const unknown = 255
+
+var (
+	kingMask   [64]uint64
+	knightMask [64]uint64
+	pawnMask   [64]uint64 // squares attacked by a white pawn
+	between    [64][64]uint64
+	straight   [64][64]bool
+	diagonal   [64][64]bool
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		f, r := sq%8, sq/8
+		for df := -2; df <= 2; df++ {
+			for dr := -2; dr <= 2; dr++ {
+				tf, tr := f+df, r+dr
+				if tf < 0 || tf > 7 || tr < 0 || tr > 7 || (df == 0 && dr == 0) {
+					continue
+				}
+				to := uint64(1) << (tr*8 + tf)
+				if abs(df) <= 1 && abs(dr) <= 1 {
+					kingMask[sq] |= to
+				}
+				if abs(df)*abs(dr) == 2 {
+					knightMask[sq] |= to
+				}
+				if dr == 1 && abs(df) == 1 {
+					pawnMask[sq] |= to
+				}
+			}
+		}
+
+		for to := 0; to < 64; to++ {
+			tf, tr := to%8, to/8
+			df, dr := tf-f, tr-r
+			switch {
+			case to == sq:
+				continue
+			case df == 0 || dr == 0:
+				straight[sq][to] = true
+			case abs(df) == abs(dr):
+				diagonal[sq][to] = true
+			default:
+				continue
+			}
+			sf, sr := sign(df), sign(dr)
+			for cf, cr := f+sf, r+sr; cf != tf || cr != tr; cf, cr = cf+sf, cr+sr {
+				between[sq][to] |= 1 << (cr*8 + cf)
+			}
+		}
+	}
+}
+
+// table holds the distance to mate of every position where the attacker has
+// a king and the table's pieces and the defender a bare king. The attacker is
+// always white in the table, black positions are mirrored when probed.
+//
+// A position is indexed by the attacker's king, the defender's king and the
+// pieces, six bits each.
+type table struct {
+	pieces []chess.PieceType
+	size   int
+	// promotions are the tables reached when a pawn promotes, indexed like pieces.
+	promotions []*table
+
+	win  []uint8 // attacker to move: plies until it mates
+	loss []uint8 // defender to move: plies until it is mated
+}
+
+type setup struct {
+	ak, dk int
+	sq     [2]int
+	occ    uint64
+}
+
+func (t *table) decode(idx int) (setup, bool) {
+	s := setup{ak: idx & 63, dk: idx >> 6 & 63}
+	s.occ = 1<<s.ak | 1<<s.dk
+	if kingMask[s.ak]&(1<<s.dk) != 0 || s.ak == s.dk {
+		return s, false
+	}
+	for i, pt := range t.pieces {
+		sq := idx >> (12 + 6*i) & 63
+		if s.occ&(1<<sq) != 0 {
+			return s, false
+		}
+		if pt == chess.Pawn && (sq < 8 || sq >= 56) {
+			return s, false
+		}
+		s.sq[i] = sq
+		s.occ |= 1 << sq
+	}
+	return s, true
+}
+
+func (t *table) index(s setup) int {
+	idx := s.ak | s.dk<<6
+	for i := range t.pieces {
+		idx |= s.sq[i] << (12 + 6*i)
+	}
+	return idx
+}
+
+// attacked reports whether the attacker attacks sq, ignoring the piece skip
+// which the defender's king is capturing.
+func (t *table) attacked(s setup, sq int, occ uint64, skip int) bool {
+	if kingMask[s.ak]&(1<<sq) != 0 {
+		return true
+	}
+	for i, pt := range t.pieces {
+		if i != skip && attacks(pt, s.sq[i], sq, occ) {
+			return true
+		}
+	}
+	return false
+}
+
+func attacks(pt chess.PieceType, from int, to int, occ uint64) bool {
+	switch pt {
+	case chess.Pawn:
+		return pawnMask[from]&(1<<to) != 0
+	case chess.Knight:
+		return knightMask[from]&(1<<to) != 0
+	case chess.Bishop:
+		return diagonal[from][to] && between[from][to]&occ == 0
+	case chess.Rook:
+		return straight[from][to] && between[from][to]&occ == 0
+	case chess.Queen:
+		return (straight[from][to] || diagonal[from][to]) && between[from][to]&occ == 0
+	}
+	return false
+}
+
+// defenderMoves counts the legal king moves of the defender that keep all
+// the attacker's pieces on the board. escape is set if the king can capture
+// a piece instead, which always leaves a draw in these tables.
+func (t *table) defenderMoves(s setup) (count int, escape bool) {
+	occ := s.occ &^ (1 << s.dk)
+	for targets := kingMask[s.dk]; targets != 0; targets &= targets - 1 {
+		to := bits.TrailingZeros64(targets)
+		captured := -1
+		for i := range t.pieces {
+			if s.sq[i] == to {
+				captured = i
+			}
+		}
+		if t.attacked(s, to, occ, captured) {
+			continue
+		}
+		if captured >= 0 {
+			escape = true
+		} else {
+			count++
+		}
+	}
+	return count, escape
+}
+
+// build solves the table by retrograde analysis: starting from the mates, it
+// walks back one ply at a time through the moves that lead into known results.
+func (t *table) build() {
+	t.win = make([]uint8, t.size)
+	t.loss = make([]uint8, t.size)
+	counts := make([]uint8, t.size)
+
+	var lost []int
+	seeds := map[int][]int{}
+	for idx := 0; idx < t.size; idx++ {
+		t.win[idx], t.loss[idx], counts[idx] = unknown, unknown, unknown
+		s, ok := t.decode(idx)
+		if !ok {
+			continue
+		}
+
+		n, escape := t.defenderMoves(s)
+		switch {
+		case escape:
+		case n > 0:
+			counts[idx] = uint8(n)
+		case t.attacked(s, s.dk, s.occ, -1):
+			t.loss[idx] = 0
+			lost = append(lost, idx)
+		}
+
+		if plies, ok := t.promote(s); ok {
+			seeds[plies] = append(seeds[plies], idx)
+		}
+	}
+
+	maxSeed := 0
+	for plies := range seeds {
+		if plies > maxSeed {
+			maxSeed = plies
+		}
+	}
+
+	for ply := 0; len(lost) > 0 || ply < maxSeed; ply += 2 {
+		var won []int
+		for _, idx := range lost {
+			t.attackerUnmoves(idx, func(pred int) {
+				if t.win[pred] == unknown {
+					t.win[pred] = uint8(ply + 1)
+					won = append(won, pred)
+				}
+			})
+		}
+		for _, idx := range seeds[ply+1] {
+			if t.win[idx] == unknown {
+				t.win[idx] = uint8(ply + 1)
+				won = append(won, idx)
+			}
+		}
+
+		lost = nil
+		for _, idx := range won {
+			t.defenderUnmoves(idx, func(pred int) {
+				if counts[pred] == unknown || t.loss[pred] != unknown {
+					return
+				}
+				counts[pred]--
+				if counts[pred] == 0 {
+					t.loss[pred] = uint8(ply + 2)
+					lost = append(lost, pred)
+				}
+			})
+		}
+	}
+}
+
+// promote returns the plies to mate reached by the attacker promoting a pawn
+// when it is to move, choosing between the promotion tables.
+func (t *table) promote(s setup) (int, bool) {
+	if t.promotions == nil || t.attacked(s, s.dk, s.occ, -1) {
+		// Not a legal position with the attacker to move.
+		return 0, false
+	}
+	best, found := 0, false
+	for i, pt := range t.pieces {
+		to := s.sq[i] + 8
+		if pt != chess.Pawn || to < 56 || s.occ&(1<<to) != 0 {
+			continue
+		}
+		for _, promoted := range t.promotions {
+			next := s
+			next.sq[i] = to
+			plies := promoted.loss[promoted.index(next)]
+			if plies != unknown && (!found || int(plies)+1 < best) {
+				best, found = int(plies)+1, true
+			}
+		}
+	}
+	return best, found
+}
+
+// attackerUnmoves calls fn with every legal position, attacker to move,
+// from which the attacker reaches the position idx with a quiet move.
+func (t *table) attackerUnmoves(idx int, fn func(int)) {
+	s, _ := t.decode(idx)
+	try := func(pred setup) {
+		pred.occ = 1<<pred.ak | 1<<pred.dk
+		for i := range t.pieces {
+			pred.occ |= 1 << pred.sq[i]
+		}
+		if !t.attacked(pred, pred.dk, pred.occ, -1) {
+			fn(t.index(pred))
+		}
+	}
+
+	for from := kingMask[s.ak] &^ s.occ &^ kingMask[s.dk]; from != 0; from &= from - 1 {
+		pred := s
+		pred.ak = bits.TrailingZeros64(from)
+		try(pred)
+	}
+	for i, pt := range t.pieces {
+		to := s.sq[i]
+		if pt == chess.Pawn {
+			if to-8 >= 8 && s.occ&(1<<(to-8)) == 0 {
+				pred := s
+				pred.sq[i] = to - 8
+				try(pred)
+				if to/8 == 3 && s.occ&(1<<(to-16)) == 0 {
+					pred.sq[i] = to - 16
+					try(pred)
+				}
+			}
+			continue
+		}
+		for from := 0; from < 64; from++ {
+			if s.occ&(1<<from) == 0 && attacks(pt, to, from, s.occ) {
+				pred := s
+				pred.sq[i] = from
+				try(pred)
+			}
+		}
+	}
+}
+
+// defenderUnmoves calls fn with every position, defender to move, from which
+// the defender's king reaches the position idx.
+func (t *table) defenderUnmoves(idx int, fn func(int)) {
+	s, _ := t.decode(idx)
+	for from := kingMask[s.dk] &^ s.occ &^ kingMask[s.ak]; from != 0; from &= from - 1 {
+		pred := s
+		pred.dk = bits.TrailingZeros64(from)
+		fn(t.index(pred))
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
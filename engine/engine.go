@@ -0,0 +1,115 @@
+// Package engine evaluates chess positions.
+//
+// Pool drives external engines speaking the UCI protocol over stdin/stdout.
+// Any executable can be used as the engine, so a scripted fake engine is
+// enough to exercise the package without a real engine installed.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoMove = errors.New("engine returned no move")
+
+// mateValue is the value of a mate in zero, used to order mates against centipawns.
+const mateValue = 100000
+
+// Score is an evaluation from the point of view of the side to move.
+type Score struct {
+	CP   int // centipawns, used when Mate is 0
+	Mate int // moves until mate, negative if the side to move is getting mated
+	// Mated is set when the side is already checkmated, which a Mate of 0
+	// could not tell from an even position. Won is the same seen from the
+	// other side; Negate swaps them.
+	Mated bool
+	Won   bool
+}
+
+// Value converts the score to a single comparable number, mates being the largest.
+func (s Score) Value() int {
+	switch {
+	case s.Won:
+		return mateValue
+	case s.Mated:
+		return -mateValue
+	case s.Mate > 0:
+		return mateValue - s.Mate
+	case s.Mate < 0:
+		return -mateValue - s.Mate
+	}
+	return s.CP
+}
+
+// Negate returns the score from the point of view of the other side.
+func (s Score) Negate() Score {
+	return Score{CP: -s.CP, Mate: -s.Mate, Mated: s.Won, Won: s.Mated}
+}
+
+func (s Score) String() string {
+	switch {
+	case s.Won:
+		return "#0"
+	case s.Mated:
+		return "#-0"
+	}
+	if s.Mate != 0 {
+		return fmt.Sprintf("#%d", s.Mate)
+	}
+	return fmt.Sprintf("%+.2f", float64(s.CP)/100)
+}
+
+// Line is one principal variation found by the engine.
+type Line struct {
+	Move  string   // first move of the line in UCI notation
+	Score Score    // evaluation after playing the line, from the side to move
+	PV    []string // whole line in UCI notation
+	Depth int
+}
+
+// Limits bounds a search. A zero Depth and MoveTime lets the engine decide.
+type Limits struct {
+	Depth    int
+	MoveTime time.Duration
+	MultiPV  int // number of lines to return, at least 1
+}
+
+// Analyzer finds the best lines of a position given as FEN.
+// Lines are ordered from best to worst.
+type Analyzer interface {
+	Analyze(ctx context.Context, fen string, limits Limits) ([]Line, error)
+}
+
+// Evaluate returns the evaluation of the position from the side to move.
+func Evaluate(ctx context.Context, a Analyzer, fen string, limits Limits) (Score, error) {
+	limits.MultiPV = 1
+	lines, err := a.Analyze(ctx, fen, limits)
+	if err != nil {
+		return Score{}, err
+	}
+	if len(lines) == 0 {
+		return Score{}, ErrNoMove
+	}
+	return lines[0].Score, nil
+}
+
+// BestMove returns the best move of the position in UCI notation.
+func BestMove(ctx context.Context, a Analyzer, fen string, limits Limits) (string, error) {
+	limits.MultiPV = 1
+	lines, err := a.Analyze(ctx, fen, limits)
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 || lines[0].Move == "" {
+		return "", ErrNoMove
+	}
+	return lines[0].Move, nil
+}
+
+// MultiPV returns the n best lines of the position.
+func MultiPV(ctx context.Context, a Analyzer, fen string, n int, limits Limits) ([]Line, error) {
+	limits.MultiPV = n
+	return a.Analyze(ctx, fen, limits)
+}
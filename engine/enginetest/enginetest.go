@@ -0,0 +1,180 @@
+// Package enginetest runs the test binary as a scripted UCI engine, so
+// packages using engine.Pool can be tested without a real engine installed.
+//
+// A test package calls Main from its TestMain; Command then returns what
+// starts the test binary as the engine instead of the tests.
+package enginetest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Environment of the fake engine process.
+const (
+	envBehaviour = "ENGINETEST_BEHAVIOUR"
+	envScript    = "ENGINETEST_SCRIPT"  // file with the Script as JSON
+	envLog       = "ENGINETEST_LOG"     // file receiving every command read
+	envState     = "ENGINETEST_STATE"   // file counting the crashes so far
+	envCrashes   = "ENGINETEST_CRASHES" // crashes before the engine behaves
+)
+
+// Behaviour is how the fake engine deviates from a well-behaved engine.
+type Behaviour string
+
+const (
+	Normal Behaviour = "normal"
+	Mute   Behaviour = "mute"  // never finishes the handshake
+	Stall  Behaviour = "stall" // the first search only ends with "stop"
+	Crash  Behaviour = "crash" // exits on "go" until Options.Crashes is reached
+)
+
+// Script maps positions, as FEN without the move counters, to the lines the
+// engine writes when asked to search them, "bestmove" included. Positions
+// missing from it get a fixed answer with three lines, see Canned.
+type Script map[string][]string
+
+// Canned is the answer to a position missing from the script: e2e4, d2d4
+// and g1f3 scored 30, 10 and mated in 2, with bounds and a stale line that
+// must be ignored.
+var Canned = []string{
+	"info depth 1 multipv 1 score cp 10 pv d2d4",
+	"info depth 8 seldepth 10 multipv 1 score cp 99 lowerbound nodes 100 pv d2d4",
+	"info depth 8 multipv 1 score cp 30 nodes 1000 pv e2e4 e7e5",
+	"info depth 8 multipv 2 score cp 10 nodes 1000 pv d2d4 e7e5",
+	"info depth 8 multipv 3 score mate -2 nodes 1000 pv g1f3 e7e5",
+	"info depth 9 multipv 3 score cp -500 upperbound pv g1f3",
+	"info string search done",
+	"bestmove e2e4 ponder e7e5",
+}
+
+// Options configure one fake engine.
+type Options struct {
+	Behaviour Behaviour // Normal if empty
+	Script    Script
+	Log       string // file receiving the commands, if not empty
+	State     string // file counting crashes, shared by restarted processes
+	Crashes   int    // number of crashes of a Crash engine
+}
+
+// Main runs the tests, or the fake engine when the binary was started by Command.
+func Main(m *testing.M) {
+	if behaviour := os.Getenv(envBehaviour); behaviour != "" {
+		run(Behaviour(behaviour))
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Command returns the path and environment that start the fake engine.
+func Command(t testing.TB, opts Options) (string, []string) {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Behaviour == "" {
+		opts.Behaviour = Normal
+	}
+	env := append(os.Environ(),
+		envBehaviour+"="+string(opts.Behaviour),
+		envLog+"="+opts.Log,
+		envState+"="+opts.State,
+		envCrashes+"="+strconv.Itoa(opts.Crashes),
+	)
+	if opts.Script != nil {
+		data, err := json.Marshal(opts.Script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "script.json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		env = append(env, envScript+"="+path)
+	}
+	return executable, env
+}
+
+// Key returns the script key of a FEN.
+func Key(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) > 4 {
+		fields = fields[:4]
+	}
+	return strings.Join(fields, " ")
+}
+
+func run(behaviour Behaviour) {
+	var log *os.File
+	if path := os.Getenv(envLog); path != "" {
+		log, _ = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	}
+	script := Script{}
+	if path := os.Getenv(envScript); path != "" {
+		data, _ := os.ReadFile(path)
+		json.Unmarshal(data, &script)
+	}
+
+	fen := ""
+	stalled, searches := false, 0
+	input := bufio.NewScanner(os.Stdin)
+	for input.Scan() {
+		command := strings.TrimSpace(input.Text())
+		if log != nil {
+			fmt.Fprintln(log, command)
+		}
+		switch {
+		case command == "uci":
+			if behaviour != Mute {
+				fmt.Println("id name enginetest")
+				fmt.Println("uciok")
+			}
+		case command == "isready":
+			fmt.Println("readyok")
+		case strings.HasPrefix(command, "position fen "):
+			fen = strings.TrimPrefix(command, "position fen ")
+		case strings.HasPrefix(command, "go"):
+			searches++
+			switch {
+			case behaviour == Crash && crashAgain():
+				os.Exit(1)
+			case behaviour == Stall && searches == 1:
+				stalled = true
+				continue
+			}
+			lines, ok := script[Key(fen)]
+			if !ok {
+				lines = Canned
+			}
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+		case command == "stop":
+			if stalled {
+				stalled = false
+				fmt.Println("bestmove e2e4")
+			}
+		case command == "quit":
+			return
+		}
+	}
+}
+
+// crashAgain counts a crash in the state file unless enough have happened.
+func crashAgain() bool {
+	path := os.Getenv(envState)
+	data, _ := os.ReadFile(path)
+	limit, _ := strconv.Atoi(os.Getenv(envCrashes))
+	if len(data) >= limit {
+		return false
+	}
+	os.WriteFile(path, append(data, 'x'), 0o644)
+	return true
+}
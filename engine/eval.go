@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"github.com/notnil/chess"
+)
+
+// Piece values in centipawns.
+var pieceValues = map[chess.PieceType]int{
+	chess.Pawn:   100,
+	chess.Knight: 320,
+	chess.Bishop: 330,
+	chess.Rook:   500,
+	chess.Queen:  900,
+	chess.King:   0,
+}
+
+// PieceValue returns the value of the piece type in centipawns.
+func PieceValue(t chess.PieceType) int {
+	return pieceValues[t]
+}
+
+// Piece-square tables from white's point of view, listed from a8 to h1.
+var pawnTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	10, 10, 20, 30, 30, 20, 10, 10,
+	5, 5, 10, 25, 25, 10, 5, 5,
+	0, 0, 0, 20, 20, 0, 0, 0,
+	5, -5, -10, 0, 0, -10, -5, 5,
+	5, 10, 10, -20, -20, 10, 10, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+var knightTable = [64]int{
+	-50, -40, -30, -30, -30, -30, -40, -50,
+	-40, -20, 0, 0, 0, 0, -20, -40,
+	-30, 0, 10, 15, 15, 10, 0, -30,
+	-30, 5, 15, 20, 20, 15, 5, -30,
+	-30, 0, 15, 20, 20, 15, 0, -30,
+	-30, 5, 10, 15, 15, 10, 5, -30,
+	-40, -20, 0, 5, 5, 0, -20, -40,
+	-50, -40, -30, -30, -30, -30, -40, -50,
+}
+
+var bishopTable = [64]int{
+	-20, -10, -10, -10, -10, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 10, 10, 5, 0, -10,
+	-10, 5, 5, 10, 10, 5, 5, -10,
+	-10, 0, 10, 10, 10, 10, 0, -10,
+	-10, 10, 10, 10, 10, 10, 10, -10,
+	-10, 5, 0, 0, 0, 0, 5, -10,
+	-20, -10, -10, -10, -10, -10, -10, -20,
+}
+
+var rookTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	5, 10, 10, 10, 10, 10, 10, 5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	0, 0, 0, 5, 5, 0, 0, 0,
+}
+
+var queenTable = [64]int{
+	-20, -10, -10, -5, -5, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 5, 5, 5, 0, -10,
+	-5, 0, 5, 5, 5, 5, 0, -5,
+	0, 0, 5, 5, 5, 5, 0, -5,
+	-10, 5, 5, 5, 5, 5, 0, -10,
+	-10, 0, 5, 0, 0, 0, 0, -10,
+	-20, -10, -10, -5, -5, -10, -10, -20,
+}
+
+var kingMiddleTable = [64]int{
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-20, -30, -30, -40, -40, -30, -30, -20,
+	-10, -20, -20, -20, -20, -20, -20, -10,
+	20, 20, 0, 0, 0, 0, 20, 20,
+	20, 30, 10, 0, 0, 10, 30, 20,
+}
+
+var kingEndTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+// evaluate scores the position statically from the side to move.
+func evaluate(pos *chess.Position) int {
+	board := pos.Board()
+
+	var pieces [64]chess.Piece
+	nonPawnMaterial := 0
+	for sq := 0; sq < 64; sq++ {
+		p := board.Piece(chess.Square(sq))
+		pieces[sq] = p
+		if p != chess.NoPiece && p.Type() != chess.Pawn && p.Type() != chess.King {
+			nonPawnMaterial += pieceValues[p.Type()]
+		}
+	}
+	endgame := nonPawnMaterial <= 2*(pieceValues[chess.Rook]+pieceValues[chess.Knight])
+
+	score := 0
+	for sq, p := range pieces {
+		if p == chess.NoPiece {
+			continue
+		}
+
+		// Tables are indexed from a8, mirror the rank for white.
+		index := sq
+		if p.Color() == chess.White {
+			index = (7-sq/8)*8 + sq%8
+		}
+
+		value := pieceValues[p.Type()]
+		switch p.Type() {
+		case chess.Pawn:
+			value += pawnTable[index]
+		case chess.Knight:
+			value += knightTable[index]
+		case chess.Bishop:
+			value += bishopTable[index]
+		case chess.Rook:
+			value += rookTable[index]
+		case chess.Queen:
+			value += queenTable[index]
+		case chess.King:
+			if endgame {
+				value += kingEndTable[index]
+			} else {
+				value += kingMiddleTable[index]
+			}
+		}
+
+		if p.Color() == pos.Turn() {
+			score += value
+		} else {
+			score -= value
+		}
+	}
+	return score
+}
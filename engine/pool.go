@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrPoolClosed = errors.New("engine pool is closed")
+
+// Config describes how to run a UCI engine.
+type Config struct {
+	Path    string
+	Args    []string
+	Env     []string          // environment of the process, inherited if nil
+	Options map[string]string // sent as "setoption" after the handshake
+
+	Size    int           // maximum number of engine processes, 1 if zero
+	Timeout time.Duration // upper bound of a single analysis, 30s if zero
+}
+
+// Pool runs analyses on a bounded set of engine processes.
+// Processes that crash or stop answering are discarded and restarted on demand.
+// Pool is safe for concurrent use.
+type Pool struct {
+	cfg Config
+
+	slots chan struct{} // one token per process that may run
+	mu    sync.Mutex
+	idle  []*process
+	done  bool
+}
+
+// NewPool starts one engine process to check the configuration and
+// returns a pool that grows up to cfg.Size processes.
+func NewPool(cfg Config) (*Pool, error) {
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	p, err := startProcess(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pool{
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.Size),
+		idle:  []*process{p},
+	}, nil
+}
+
+// Analyze implements Analyzer. A search that outlives the pool timeout is
+// stopped, and a crashed engine is restarted once before giving up.
+func (pool *Pool) Analyze(ctx context.Context, fen string, limits Limits) ([]Line, error) {
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-pool.slots }()
+
+	ctx, cancel := context.WithTimeout(ctx, pool.cfg.Timeout)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		p, err := pool.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		lines, err := p.analyze(ctx, fen, limits)
+		if err == nil {
+			pool.release(p)
+			return lines, nil
+		}
+
+		if err == ErrEngineExited && attempt == 0 {
+			p.kill()
+			continue
+		}
+		if p.alive() {
+			// The search was stopped in time, the engine can be reused.
+			pool.release(p)
+		} else {
+			p.kill()
+		}
+		return nil, err
+	}
+}
+
+// Close shuts down all idle engines. Engines in use are shut down when released.
+func (pool *Pool) Close() {
+	pool.mu.Lock()
+	idle := pool.idle
+	pool.idle = nil
+	pool.done = true
+	pool.mu.Unlock()
+
+	for _, p := range idle {
+		p.close()
+	}
+}
+
+func (pool *Pool) acquire(ctx context.Context) (*process, error) {
+	pool.mu.Lock()
+	if pool.done {
+		pool.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	for len(pool.idle) > 0 {
+		p := pool.idle[len(pool.idle)-1]
+		pool.idle = pool.idle[:len(pool.idle)-1]
+		if p.alive() {
+			pool.mu.Unlock()
+			return p, nil
+		}
+	}
+	pool.mu.Unlock()
+
+	return startProcess(ctx, pool.cfg)
+}
+
+func (pool *Pool) release(p *process) {
+	pool.mu.Lock()
+	if pool.done {
+		pool.mu.Unlock()
+		p.close()
+		return
+	}
+	pool.idle = append(pool.idle, p)
+	pool.mu.Unlock()
+}
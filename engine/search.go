@@ -0,0 +1,313 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+const (
+	infinity    = 1 << 30
+	mateScore   = 1 << 20
+	maxQuiesce  = 8
+	checkPeriod = 512 // nodes between deadline checks
+)
+
+var errSearchAborted = errors.New("search aborted")
+
+// Searcher is a small alpha-beta engine working directly on notnil/chess positions.
+// It needs no external binary, so it can be used wherever the bot runs.
+type Searcher struct {
+	Depth    int           // maximum depth in plies, 4 if zero
+	MoveTime time.Duration // time budget of a search, unlimited if zero
+}
+
+// search holds the state of a single search.
+type search struct {
+	ctx      context.Context
+	deadline time.Time
+	nodes    int
+	// path has the positions from the root to the current node, by repetitionKey.
+	path []string
+}
+
+type rootMove struct {
+	move  *chess.Move
+	score int
+	pv    []string
+}
+
+// Analyze implements Analyzer with iterative deepening. If the time runs out,
+// the result of the last completed depth is returned.
+func (s *Searcher) Analyze(ctx context.Context, fen string, limits Limits) ([]Line, error) {
+	pos := &chess.Position{}
+	if err := pos.UnmarshalText([]byte(fen)); err != nil {
+		return nil, err
+	}
+
+	depth := limits.Depth
+	if depth <= 0 {
+		depth = s.Depth
+	}
+	if depth <= 0 {
+		depth = 4
+	}
+	moveTime := limits.MoveTime
+	if moveTime <= 0 {
+		moveTime = s.MoveTime
+	}
+	multiPV := limits.MultiPV
+	if multiPV < 1 {
+		multiPV = 1
+	}
+
+	st := &search{ctx: ctx}
+	if moveTime > 0 {
+		st.deadline = time.Now().Add(moveTime)
+	}
+
+	moves := pos.ValidMoves()
+	if len(moves) == 0 {
+		return nil, nil
+	}
+	orderMoves(pos, moves)
+	roots := make([]rootMove, len(moves))
+	for i, m := range moves {
+		roots[i] = rootMove{move: m}
+	}
+
+	var completed []rootMove
+	reached := 0
+	for d := 1; d <= depth; d++ {
+		result, err := st.searchRoot(pos, roots, d, multiPV)
+		if err != nil {
+			if completed == nil {
+				return nil, err
+			}
+			break
+		}
+		completed = result
+		reached = d
+		roots = result
+		if multiPV == 1 && abs(result[0].score) >= mateScore-d {
+			break // a forced mate was found, deeper searches cannot improve it
+		}
+	}
+
+	lines := make([]Line, 0, multiPV)
+	for i := 0; i < multiPV && i < len(completed); i++ {
+		lines = append(lines, Line{
+			Move:  completed[i].move.String(),
+			Score: toScore(completed[i].score),
+			PV:    completed[i].pv,
+			Depth: reached,
+		})
+	}
+	return lines, nil
+}
+
+// searchRoot scores the root moves at the given depth. Once multiPV lines are
+// known, other moves are only searched far enough to prove they are worse,
+// so their scores are upper bounds.
+func (st *search) searchRoot(pos *chess.Position, roots []rootMove, depth int, multiPV int) ([]rootMove, error) {
+	result := make([]rootMove, len(roots))
+	copy(result, roots)
+
+	st.path = []string{repetitionKey(pos)}
+	alpha := -infinity
+	for i := range result {
+		child := pos.Update(result[i].move)
+		var score int
+		var pv []string
+		var err error
+		if i < multiPV {
+			score, pv, err = st.negamax(child, depth-1, 1, -infinity, infinity)
+		} else {
+			score, pv, err = st.negamax(child, depth-1, 1, -infinity, -alpha)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[i].score = -score
+		result[i].pv = append([]string{result[i].move.String()}, pv...)
+
+		// alpha is the worst score among the lines that must be exact.
+		if i == multiPV-1 || (i >= multiPV && -score > alpha) {
+			alpha = worstOf(result[:i+1], multiPV)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].score > result[j].score
+	})
+	return result, nil
+}
+
+func worstOf(moves []rootMove, n int) int {
+	scores := make([]int, len(moves))
+	for i, m := range moves {
+		scores[i] = m.score
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+	if len(scores) < n {
+		return -infinity
+	}
+	return scores[n-1]
+}
+
+func (st *search) negamax(pos *chess.Position, depth int, ply int, alpha int, beta int) (int, []string, error) {
+	if err := st.tick(); err != nil {
+		return 0, nil, err
+	}
+
+	moves := pos.ValidMoves()
+	if len(moves) == 0 {
+		if pos.Status() == chess.Checkmate {
+			return -mateScore + ply, nil, nil
+		}
+		return 0, nil, nil
+	}
+	if pos.HalfMoveClock() >= 100 {
+		return 0, nil, nil
+	}
+	// A position already on the path can be repeated again until the third
+	// time, so the side that wants the draw gets it.
+	key := repetitionKey(pos)
+	for _, seen := range st.path {
+		if seen == key {
+			return 0, nil, nil
+		}
+	}
+	if depth <= 0 {
+		score, err := st.quiesce(pos, 0, alpha, beta)
+		return score, nil, err
+	}
+
+	st.path = append(st.path, key)
+	defer func() { st.path = st.path[:len(st.path)-1] }()
+
+	orderMoves(pos, moves)
+	var bestPV []string
+	for _, m := range moves {
+		score, pv, err := st.negamax(pos.Update(m), depth-1, ply+1, -beta, -alpha)
+		if err != nil {
+			return 0, nil, err
+		}
+		score = -score
+		if score >= beta {
+			return beta, nil, nil
+		}
+		if score > alpha {
+			alpha = score
+			bestPV = append([]string{m.String()}, pv...)
+		}
+	}
+	return alpha, bestPV, nil
+}
+
+// quiesce extends the search with captures and promotions until the position is quiet.
+func (st *search) quiesce(pos *chess.Position, depth int, alpha int, beta int) (int, error) {
+	if err := st.tick(); err != nil {
+		return 0, err
+	}
+
+	standPat := evaluate(pos)
+	if standPat >= beta {
+		return beta, nil
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+	if depth >= maxQuiesce {
+		return alpha, nil
+	}
+
+	moves := pos.ValidMoves()
+	orderMoves(pos, moves)
+	for _, m := range moves {
+		if !m.HasTag(chess.Capture) && m.Promo() == chess.NoPieceType {
+			continue
+		}
+		score, err := st.quiesce(pos.Update(m), depth+1, -beta, -alpha)
+		if err != nil {
+			return 0, err
+		}
+		score = -score
+		if score >= beta {
+			return beta, nil
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha, nil
+}
+
+// repetitionKey identifies a position for repetitions: the FEN without the
+// move counters.
+func repetitionKey(pos *chess.Position) string {
+	fields := strings.Fields(pos.String())
+	return strings.Join(fields[:4], " ")
+}
+
+// tick counts a node and reports whether the search must stop.
+func (st *search) tick() error {
+	st.nodes++
+	if st.nodes%checkPeriod != 0 {
+		return nil
+	}
+	if st.ctx.Err() != nil {
+		return errSearchAborted
+	}
+	if !st.deadline.IsZero() && time.Now().After(st.deadline) {
+		return errSearchAborted
+	}
+	return nil
+}
+
+// orderMoves sorts promotions and captures of valuable pieces by cheap pieces first.
+func orderMoves(pos *chess.Position, moves []*chess.Move) {
+	board := pos.Board()
+	priority := func(m *chess.Move) int {
+		p := 0
+		if m.Promo() != chess.NoPieceType {
+			p += 10 * pieceValues[m.Promo()]
+		}
+		if m.HasTag(chess.Capture) {
+			victim := pieceValues[chess.Pawn]
+			if captured := board.Piece(m.S2()); captured != chess.NoPiece {
+				victim = pieceValues[captured.Type()]
+			}
+			p += 10*victim - pieceValues[board.Piece(m.S1()).Type()]/10 + 1
+		}
+		if m.HasTag(chess.Check) {
+			p += 50
+		}
+		return p
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return priority(moves[i]) > priority(moves[j])
+	})
+}
+
+// toScore converts an internal score to centipawns or moves to mate.
+func toScore(score int) Score {
+	switch {
+	case score > mateScore-1000:
+		return Score{Mate: (mateScore - score + 1) / 2}
+	case score < -mateScore+1000:
+		return Score{Mate: -(mateScore + score) / 2}
+	}
+	return Score{CP: score}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
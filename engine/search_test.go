@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/notnil/chess"
+)
+
+func mustPosition(t *testing.T, fen string) *chess.Position {
+	t.Helper()
+	pos := &chess.Position{}
+	if err := pos.UnmarshalText([]byte(fen)); err != nil {
+		t.Fatalf("bad FEN %q: %v", fen, err)
+	}
+	return pos
+}
+
+func TestSearcherMates(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		move string
+		mate int
+	}{
+		{"back rank, white", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1a8", 1},
+		{"back rank, black", "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 1", "a8a1", 1},
+		{"queen sacrifice", "r1b2k1r/ppp1bppp/8/1B1Q4/5q2/2P5/PPP2PPP/R3R1K1 w - - 1 1", "d5d8", 2},
+		{"queen sacrifice, black", mirror("r1b2k1r/ppp1bppp/8/1B1Q4/5q2/2P5/PPP2PPP/R3R1K1 w - - 1 1"), "d4d1", 2},
+	}
+	s := &Searcher{Depth: 4}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines, err := s.Analyze(context.Background(), test.fen, Limits{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lines) != 1 || lines[0].Move != test.move || lines[0].Score != (Score{Mate: test.mate}) {
+				t.Errorf("lines = %+v, want %s mating in %d", lines, test.move, test.mate)
+			}
+		})
+	}
+}
+
+func TestSearcherMultiPV(t *testing.T) {
+	// White can take the queen, the rook or a pawn.
+	fen := "4k3/8/8/3q4/8/5r2/4PP2/3QK1N1 w - - 0 1"
+	lines, err := MultiPV(context.Background(), &Searcher{Depth: 2}, fen, 3, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if lines[0].Move != "d1d5" {
+		t.Errorf("best line %s, want d1d5", lines[0].Move)
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i].Score.Value() > lines[i-1].Score.Value() {
+			t.Errorf("line %d (%s %v) is better than line %d (%s %v)", i+1, lines[i].Move, lines[i].Score, i, lines[i-1].Move, lines[i-1].Score)
+		}
+		if lines[i].Move == lines[i-1].Move {
+			t.Errorf("line %d repeats %s", i+1, lines[i].Move)
+		}
+	}
+
+	best, err := BestMove(context.Background(), &Searcher{Depth: 2}, fen, Limits{})
+	if err != nil || best != lines[0].Move {
+		t.Errorf("BestMove = %q, %v, want the first line %s", best, err, lines[0].Move)
+	}
+}
+
+func TestSearcherStalemate(t *testing.T) {
+	s := &Searcher{}
+	lines, err := s.Analyze(context.Background(), "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Limits{})
+	if err != nil || lines != nil {
+		t.Errorf("stalemated root = %+v, %v, want no lines", lines, err)
+	}
+
+	st := &search{ctx: context.Background()}
+	score, _, err := st.negamax(mustPosition(t, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"), 3, 1, -infinity, infinity)
+	if err != nil || score != 0 {
+		t.Errorf("stalemate scored %d, %v, want 0", score, err)
+	}
+
+	// Qb3 would stalemate the white king, every other queen move along the
+	// a-file mates.
+	fen := "8/8/8/3q4/8/8/2k5/K7 b - - 0 1"
+	lines, err = MultiPV(context.Background(), &Searcher{Depth: 2}, fen, 64, Limits{})
+	if err != nil || len(lines) == 0 {
+		t.Fatalf("lines = %+v, %v", lines, err)
+	}
+	if lines[0].Score != (Score{Mate: 1}) {
+		t.Errorf("best line %+v, want a mate in 1", lines[0])
+	}
+	for _, line := range lines {
+		if line.Move == "d5b3" && line.Score != (Score{}) {
+			t.Errorf("stalemating move scored %v, want 0", line.Score)
+		}
+	}
+}
+
+func TestSearcherRepetition(t *testing.T) {
+	// White is a queen up, but the position was already reached on the path.
+	pos := mustPosition(t, "4k3/8/8/8/8/8/8/3QK3 w - - 4 10")
+	st := &search{ctx: context.Background(), path: []string{repetitionKey(pos), "other"}}
+	if score, _, err := st.negamax(pos, 2, 2, -infinity, infinity); err != nil || score != 0 {
+		t.Errorf("repeated position scored %d, %v, want 0", score, err)
+	}
+
+	// The move counters do not make positions different.
+	again := mustPosition(t, "4k3/8/8/8/8/8/8/3QK3 w - - 8 12")
+	if repetitionKey(pos) != repetitionKey(again) {
+		t.Error("the same position has different repetition keys")
+	}
+
+	st = &search{ctx: context.Background(), path: []string{"other"}}
+	if score, _, err := st.negamax(pos, 2, 2, -infinity, infinity); err != nil || score < 500 {
+		t.Errorf("new position scored %d, %v, want white far ahead", score, err)
+	}
+	if len(st.path) != 1 {
+		t.Errorf("path has %d positions after the search, want it restored", len(st.path))
+	}
+}
+
+// mirror swaps the colours of the position and flips the board vertically.
+func mirror(fen string) string {
+	fields := strings.Fields(fen)
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	swap := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsUpper(r) {
+				return unicode.ToLower(r)
+			}
+			return unicode.ToUpper(r)
+		}, s)
+	}
+	fields[0] = swap(strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	if fields[2] != "-" {
+		fields[2] = swap(fields[2])
+	}
+	if fields[3] != "-" {
+		rank := '3'
+		if fields[3][1] == '3' {
+			rank = '6'
+		}
+		fields[3] = string(fields[3][0]) + string(rank)
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r1bqkbnr/pppp1ppp/2n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 3 3",
+		"r1bq1rk1/pp2bppp/2n1pn2/3p4/2PP4/2N1PN2/PP3PPP/R2QKB1R w KQ - 0 8",
+		"8/5k2/3p4/1p1Pp2p/pP2Pp1P/P4P1K/8/8 b - - 0 1",
+		"4k3/8/8/3q4/8/5r2/4PP2/3QK1N1 w - - 0 1",
+	}
+	for _, fen := range fens {
+		pos := mustPosition(t, fen)
+		mirrored := mustPosition(t, mirror(fen))
+		if a, b := evaluate(pos), evaluate(mirrored); a != b {
+			t.Errorf("evaluate(%q) = %d, but %d mirrored", fen, a, b)
+		}
+	}
+	if score := evaluate(mustPosition(t, fens[0])); score != 0 {
+		t.Errorf("starting position scored %d, want 0", score)
+	}
+}
@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrEngineExited = errors.New("engine process exited")
+
+// How long an engine gets to answer "stop" before it is killed.
+const stopGrace = 2 * time.Second
+
+// process is a running UCI engine.
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan string   // closed when the engine stops writing
+	exited chan struct{} // closed when the engine exits
+	killed bool
+}
+
+func startProcess(ctx context.Context, cfg Config) (*process, error) {
+	cmd := exec.Command(cfg.Path, cfg.Args...)
+	if cfg.Env != nil {
+		cmd.Env = cfg.Env
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start engine %s: %w", cfg.Path, err)
+	}
+
+	p := &process{
+		cmd:    cmd,
+		stdin:  stdin,
+		lines:  make(chan string, 64),
+		exited: make(chan struct{}),
+	}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			p.lines <- strings.TrimSpace(scanner.Text())
+		}
+		close(p.lines)
+		cmd.Wait()
+		close(p.exited)
+	}()
+
+	if err := p.send("uci"); err != nil {
+		p.kill()
+		return nil, err
+	}
+	if _, err := p.waitFor(ctx, "uciok"); err != nil {
+		p.kill()
+		return nil, fmt.Errorf("engine handshake: %w", err)
+	}
+
+	names := make([]string, 0, len(cfg.Options))
+	for name := range cfg.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := p.send(fmt.Sprintf("setoption name %s value %s", name, cfg.Options[name])); err != nil {
+			p.kill()
+			return nil, err
+		}
+	}
+
+	if err := p.ready(ctx); err != nil {
+		p.kill()
+		return nil, fmt.Errorf("engine handshake: %w", err)
+	}
+	return p, nil
+}
+
+func (p *process) send(command string) error {
+	if _, err := io.WriteString(p.stdin, command+"\n"); err != nil {
+		return ErrEngineExited
+	}
+	return nil
+}
+
+func (p *process) ready(ctx context.Context) error {
+	if err := p.send("isready"); err != nil {
+		return err
+	}
+	_, err := p.waitFor(ctx, "readyok")
+	return err
+}
+
+// waitFor reads lines until one starts with the token and returns it.
+func (p *process) waitFor(ctx context.Context, token string) (string, error) {
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return "", ErrEngineExited
+			}
+			if line == token || strings.HasPrefix(line, token+" ") {
+				return line, nil
+			}
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// analyze searches the position and collects the lines reported before "bestmove".
+// If the context ends first, the search is stopped and the process must be discarded
+// unless it answered in time.
+func (p *process) analyze(ctx context.Context, fen string, limits Limits) ([]Line, error) {
+	multiPV := limits.MultiPV
+	if multiPV < 1 {
+		multiPV = 1
+	}
+
+	commands := []string{
+		fmt.Sprintf("setoption name MultiPV value %d", multiPV),
+		"position fen " + fen,
+		goCommand(limits),
+	}
+	for _, command := range commands {
+		if err := p.send(command); err != nil {
+			return nil, err
+		}
+	}
+
+	lines := map[int]Line{}
+	var bestMove string
+	for bestMove == "" {
+		select {
+		case text, ok := <-p.lines:
+			if !ok {
+				return nil, ErrEngineExited
+			}
+			switch {
+			case strings.HasPrefix(text, "info "):
+				if index, line, ok := parseInfo(text); ok {
+					lines[index] = line
+				}
+			case strings.HasPrefix(text, "bestmove"):
+				fields := strings.Fields(text)
+				bestMove = "(none)"
+				if len(fields) > 1 {
+					bestMove = fields[1]
+				}
+			}
+		case <-ctx.Done():
+			p.stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	result := make([]Line, 0, len(lines))
+	for i := 1; i <= multiPV; i++ {
+		if line, ok := lines[i]; ok {
+			result = append(result, line)
+		}
+	}
+	// Some engines report the best move without a pv, keep it usable.
+	if len(result) == 0 && bestMove != "(none)" && bestMove != "0000" {
+		result = append(result, Line{Move: bestMove, PV: []string{bestMove}})
+	}
+	return result, nil
+}
+
+// stop interrupts the search and waits for the engine to settle.
+func (p *process) stop() {
+	if p.send("stop") != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopGrace)
+	defer cancel()
+	if _, err := p.waitFor(ctx, "bestmove"); err != nil {
+		p.kill()
+	}
+}
+
+// alive reports whether the process can take another search.
+func (p *process) alive() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return !p.killed
+	}
+}
+
+func (p *process) close() {
+	p.send("quit")
+	p.stdin.Close()
+	go p.drain()
+	select {
+	case <-p.exited:
+	case <-time.After(stopGrace):
+		p.kill()
+	}
+}
+
+// kill ends the process. The caller must not read its lines afterwards.
+func (p *process) kill() {
+	p.killed = true
+	p.cmd.Process.Kill()
+	go p.drain()
+}
+
+// drain discards the remaining output so the reader can finish.
+func (p *process) drain() {
+	for range p.lines {
+	}
+}
+
+func goCommand(limits Limits) string {
+	command := "go"
+	if limits.Depth > 0 {
+		command += fmt.Sprintf(" depth %d", limits.Depth)
+	}
+	if limits.MoveTime > 0 {
+		command += fmt.Sprintf(" movetime %d", limits.MoveTime.Milliseconds())
+	}
+	if command == "go" {
+		command += " depth 12"
+	}
+	return command
+}
+
+// parseInfo reads an "info" line that carries a principal variation.
+// It returns the multipv index of the line.
+func parseInfo(text string) (int, Line, bool) {
+	fields := strings.Fields(text)
+	index := 1
+	var line Line
+	hasScore := false
+
+	for i := 1; i < len(fields); i++ {
+		switch fields[i] {
+		case "depth":
+			if i+1 < len(fields) {
+				line.Depth, _ = strconv.Atoi(fields[i+1])
+				i++
+			}
+		case "multipv":
+			if i+1 < len(fields) {
+				index, _ = strconv.Atoi(fields[i+1])
+				i++
+			}
+		case "score":
+			if i+2 < len(fields) {
+				value, err := strconv.Atoi(fields[i+2])
+				if err != nil {
+					return 0, Line{}, false
+				}
+				switch fields[i+1] {
+				case "cp":
+					line.Score = Score{CP: value}
+				case "mate":
+					line.Score = Score{Mate: value, Mated: value == 0}
+				}
+				hasScore = true
+				i += 2
+			}
+		case "lowerbound", "upperbound":
+			// Bounds are not exact evaluations, wait for the next report.
+			return 0, Line{}, false
+		case "pv":
+			line.PV = append([]string(nil), fields[i+1:]...)
+			i = len(fields)
+		case "string":
+			i = len(fields)
+		}
+	}
+
+	// A mated side has no move to show, the score alone is the result.
+	if !hasScore || (len(line.PV) == 0 && !line.Score.Mated) {
+		return 0, Line{}, false
+	}
+	if len(line.PV) > 0 {
+		line.Move = line.PV[0]
+	}
+	return index, line, true
+}
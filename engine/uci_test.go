@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hunsuChess/engine/enginetest"
+)
+
+const (
+	startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	// Fool's mate, white to move and checkmated.
+	matedFEN = "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"
+)
+
+func TestMain(m *testing.M) {
+	enginetest.Main(m)
+}
+
+// fakeConfig runs the scripted fake engine.
+func fakeConfig(t *testing.T, opts enginetest.Options) Config {
+	t.Helper()
+	if opts.Script == nil {
+		opts.Script = enginetest.Script{
+			enginetest.Key(matedFEN): {"info depth 0 score mate 0", "bestmove (none)"},
+		}
+	}
+	path, env := enginetest.Command(t, opts)
+	return Config{Path: path, Env: env, Size: 1, Timeout: 5 * time.Second}
+}
+
+func newFakePool(t *testing.T, cfg Config) *Pool {
+	t.Helper()
+	pool, err := NewPool(cfg)
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestHandshake(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "commands")
+	cfg := fakeConfig(t, enginetest.Options{Log: log})
+	cfg.Options = map[string]string{"Threads": "1", "Hash": "16"}
+	newFakePool(t, cfg)
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{"uci", "setoption name Hash value 16", "setoption name Threads value 1", "isready"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	cfg := fakeConfig(t, enginetest.Options{Behaviour: enginetest.Mute})
+	cfg.Timeout = 200 * time.Millisecond
+	if _, err := NewPool(cfg); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NewPool with a mute engine: %v, want a deadline error", err)
+	}
+}
+
+func TestAnalyzeMultiPV(t *testing.T) {
+	pool := newFakePool(t, fakeConfig(t, enginetest.Options{}))
+
+	lines, err := MultiPV(context.Background(), pool, startFEN, 3, Limits{Depth: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Line{
+		{Move: "e2e4", Score: Score{CP: 30}, PV: []string{"e2e4", "e7e5"}, Depth: 8},
+		{Move: "d2d4", Score: Score{CP: 10}, PV: []string{"d2d4", "e7e5"}, Depth: 8},
+		{Move: "g1f3", Score: Score{Mate: -2}, PV: []string{"g1f3", "e7e5"}, Depth: 8},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %+v, want %+v", lines, want)
+	}
+
+	move, err := BestMove(context.Background(), pool, startFEN, Limits{Depth: 8})
+	if err != nil || move != "e2e4" {
+		t.Errorf("BestMove = %q, %v, want e2e4", move, err)
+	}
+}
+
+func TestAnalyzeMated(t *testing.T) {
+	pool := newFakePool(t, fakeConfig(t, enginetest.Options{}))
+
+	score, err := Evaluate(context.Background(), pool, matedFEN, Limits{Depth: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !score.Mated || score.Value() >= (Score{Mate: -1}).Value() {
+		t.Errorf("score of a mated position = %+v, want mated", score)
+	}
+	if won := score.Negate(); !won.Won || won.Value() <= (Score{Mate: 1}).Value() {
+		t.Errorf("negated score = %+v, want won", won)
+	}
+	if _, err := BestMove(context.Background(), pool, matedFEN, Limits{Depth: 8}); err != ErrNoMove {
+		t.Errorf("BestMove of a mated position: %v, want ErrNoMove", err)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		text  string
+		index int
+		line  Line
+		ok    bool
+	}{
+		{"info depth 12 multipv 2 score cp -35 nodes 10 pv e7e5 g1f3", 2, Line{Move: "e7e5", Score: Score{CP: -35}, PV: []string{"e7e5", "g1f3"}, Depth: 12}, true},
+		{"info depth 3 score mate 2 pv d8h4", 1, Line{Move: "d8h4", Score: Score{Mate: 2}, PV: []string{"d8h4"}, Depth: 3}, true},
+		{"info depth 0 score mate 0", 1, Line{Score: Score{Mated: true}}, true},
+		{"info depth 0 score cp 0", 0, Line{}, false},
+		{"info depth 9 score cp 40 lowerbound pv e2e4", 0, Line{}, false},
+		{"info depth 9 score cp 40 upperbound pv e2e4", 0, Line{}, false},
+		{"info depth 9 nodes 100 nps 1000", 0, Line{}, false},
+		{"info string pv e2e4 score cp 10", 0, Line{}, false},
+	}
+	for _, test := range tests {
+		index, line, ok := parseInfo(test.text)
+		if ok != test.ok || index != test.index || !reflect.DeepEqual(line, test.line) {
+			t.Errorf("parseInfo(%q) = %d, %+v, %v, want %d, %+v, %v", test.text, index, line, ok, test.index, test.line, test.ok)
+		}
+	}
+}
+
+func TestTimeoutStopsAndReuses(t *testing.T) {
+	pool := newFakePool(t, fakeConfig(t, enginetest.Options{Behaviour: enginetest.Stall}))
+	first := pool.idle[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Analyze(ctx, startFEN, Limits{Depth: 8}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stalled search: %v, want a deadline error", err)
+	}
+	if len(pool.idle) != 1 || pool.idle[0] != first || !first.alive() {
+		t.Fatal("the engine answered stop but was not kept")
+	}
+
+	lines, err := pool.Analyze(context.Background(), startFEN, Limits{Depth: 8})
+	if err != nil || len(lines) != 1 || lines[0].Move != "e2e4" {
+		t.Errorf("search after the stop = %+v, %v", lines, err)
+	}
+	if pool.idle[0] != first {
+		t.Error("the engine was restarted instead of reused")
+	}
+}
+
+func TestRestartAfterCrash(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "crashes")
+	pool := newFakePool(t, fakeConfig(t, enginetest.Options{Behaviour: enginetest.Crash, State: state, Crashes: 1}))
+	first := pool.idle[0]
+
+	lines, err := pool.Analyze(context.Background(), startFEN, Limits{Depth: 8})
+	if err != nil || len(lines) == 0 {
+		t.Fatalf("search after one crash = %+v, %v", lines, err)
+	}
+	if first.alive() || pool.idle[0] == first {
+		t.Error("the crashed engine was not replaced")
+	}
+}
+
+func TestRestartOnlyOnce(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "crashes")
+	pool := newFakePool(t, fakeConfig(t, enginetest.Options{Behaviour: enginetest.Crash, State: state, Crashes: 5}))
+
+	if _, err := pool.Analyze(context.Background(), startFEN, Limits{Depth: 8}); err != ErrEngineExited {
+		t.Fatalf("search with a crashing engine: %v, want ErrEngineExited", err)
+	}
+	data, _ := os.ReadFile(state)
+	if len(data) != 2 {
+		t.Errorf("engine crashed %d times, want 2", len(data))
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	pool, err := NewPool(fakeConfig(t, enginetest.Options{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := pool.idle[0]
+	pool.Close()
+
+	select {
+	case <-p.exited:
+	case <-time.After(stopGrace + time.Second):
+		t.Fatal("the idle engine did not exit")
+	}
+	if _, err := pool.Analyze(context.Background(), startFEN, Limits{}); err != ErrPoolClosed {
+		t.Errorf("Analyze after Close: %v, want ErrPoolClosed", err)
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/notnil/chess"
+)
+
+var (
+	ErrNotCaptain     = errors.New("not the captain of the team to move")
+	ErrAlreadyVetoed  = errors.New("already vetoed this turn")
+	ErrCaptainNotTeam = errors.New("candidate is not a teammate")
+)
+
+// Captain returns the captain of the team, or an empty string.
+func (game *Game) Captain(team string) string {
+	if team == "white" {
+		return game.WhiteCaptain
+	}
+	return game.BlackCaptain
+}
+
+func (game *Game) setCaptain(team string, id string) {
+	if team == "white" {
+		game.WhiteCaptain = id
+	} else {
+		game.BlackCaptain = id
+	}
+}
+
+// TurnCaptain returns the captain of the team to move.
+func (game *Game) TurnCaptain() string {
+	if !game.Turn {
+		return game.WhiteCaptain
+	}
+	return game.BlackCaptain
+}
+
+// AppointCaptain makes the player captain of their team, used by admins.
+func (game *Game) AppointCaptain(id string) error {
+	team, ok := game.GetPlayerTeam(id)
+	if !ok {
+		return ErrNotJoined
+	}
+	game.setCaptain(team, id)
+	game.logEvent(fmt.Sprintf("관리자가 <@%s>님을 %s 주장으로 임명했습니다.", id, teamName(team)))
+	return nil
+}
+
+// VoteCaptain records the player's vote in the captain election of their team.
+// The candidate becomes captain once more than half of the team votes for them.
+// It returns true if the vote elected a new captain.
+func (game *Game) VoteCaptain(id string, candidate string) (bool, error) {
+	team, ok := game.GetPlayerTeam(id)
+	if !ok {
+		return false, ErrNotJoined
+	}
+	players := game.teamOf(id)
+	if _, ok := players[candidate]; !ok {
+		return false, ErrCaptainNotTeam
+	}
+	players[id].CaptainVote = candidate
+
+	votes := 0
+	for _, player := range players {
+		if player.CaptainVote == candidate {
+			votes++
+		}
+	}
+	if votes*2 <= len(players) || game.Captain(team) == candidate {
+		return false, nil
+	}
+
+	game.setCaptain(team, candidate)
+	game.logEvent(fmt.Sprintf("<@%s>님이 %d표로 %s 주장에 선출되었습니다.", candidate, votes, teamName(team)))
+	return true, nil
+}
+
+// Veto removes a candidate move from the current turn. The captain of the team
+// to move can veto one move per turn, and votes for it are discarded.
+func (game *Game) Veto(id string, moveStr string) (string, error) {
+	if game.TurnCaptain() != id || id == "" {
+		return "", ErrNotCaptain
+	}
+	if game.Vetoed != "" {
+		return "", ErrAlreadyVetoed
+	}
+
+	move := game.findMove(moveStr)
+	if move == nil {
+		return "", ErrInvalidMove
+	}
+	game.Vetoed = move.String()
+
+	discarded := 0
+	for _, player := range game.currentPlayers() {
+		if player.Move == game.Vetoed {
+			player.Move = ""
+			player.clearReason()
+			discarded++
+		}
+	}
+
+	san := chess.AlgebraicNotation{}.Encode(game.ChessGame.Position(), move)
+	game.logEvent(fmt.Sprintf("주장 <@%s>님이 **%s**에 거부권을 행사했습니다. (%d표 무효)", id, san, discarded))
+	return san, nil
+}
+
+// findMove matches a move in SAN or UCI notation against the legal moves.
+func (game *Game) findMove(moveStr string) *chess.Move {
+	for _, move := range game.ChessGame.ValidMoves() {
+		san := chess.AlgebraicNotation{}.Encode(game.ChessGame.Position(), move)
+		if san == moveStr || move.String() == moveStr {
+			return move
+		}
+	}
+	return nil
+}
+
+func teamName(team string) string {
+	if team == "white" {
+		return "백팀"
+	}
+	return "흑팀"
+}
@@ -0,0 +1,68 @@
+package game
+
+import "testing"
+
+func TestVoteCaptain(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c", "d"}, []string{"x"})
+
+	if _, err := game.VoteCaptain("nobody", "a"); err != ErrNotJoined {
+		t.Errorf("vote of a stranger: %v, want ErrNotJoined", err)
+	}
+	if _, err := game.VoteCaptain("a", "x"); err != ErrCaptainNotTeam {
+		t.Errorf("vote for the other team: %v, want ErrCaptainNotTeam", err)
+	}
+
+	// Two of four votes tie with the other two, nobody has a majority.
+	for _, v := range [][2]string{{"a", "b"}, {"b", "b"}, {"c", "c"}, {"d", "c"}} {
+		if elected, err := game.VoteCaptain(v[0], v[1]); elected || err != nil {
+			t.Fatalf("vote of %s for %s: %v, %v, want no captain yet", v[0], v[1], elected, err)
+		}
+	}
+	if captain := game.Captain("white"); captain != "" {
+		t.Fatalf("captain %q elected by a tie", captain)
+	}
+
+	// Changing a vote breaks the tie.
+	if elected, err := game.VoteCaptain("a", "c"); !elected || err != nil {
+		t.Fatalf("majority vote: %v, %v, want c elected", elected, err)
+	}
+	if game.Captain("white") != "c" || game.TurnCaptain() != "c" || game.Captain("black") != "" {
+		t.Errorf("captains %q and %q, want c for white only", game.WhiteCaptain, game.BlackCaptain)
+	}
+	// More votes for the captain do not elect them again.
+	if elected, _ := game.VoteCaptain("b", "c"); elected {
+		t.Error("vote for the sitting captain elected them again")
+	}
+
+	// A re-vote replaces the captain once the majority moves.
+	for _, id := range []string{"a", "b"} {
+		game.VoteCaptain(id, "d")
+	}
+	if elected, _ := game.VoteCaptain("c", "d"); !elected || game.Captain("white") != "d" {
+		t.Errorf("captain %q after the re-vote, want d", game.Captain("white"))
+	}
+}
+
+func TestCaptainTieBreak(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c"}, []string{"x"})
+	if err := game.AppointCaptain("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The captain's choice decides between the tied moves.
+	vote(t, game, "a", "e4")
+	vote(t, game, "b", "d4")
+	if move := nextTurn(t, game); move != "e2e4" {
+		t.Errorf("played %s, want the captain's e2e4", move)
+	}
+
+	// It does not beat a majority.
+	vote(t, game, "x", "e5")
+	nextTurn(t, game)
+	vote(t, game, "a", "Nf3")
+	vote(t, game, "b", "d4")
+	vote(t, game, "c", "d4")
+	if move := nextTurn(t, game); move != "d2d4" {
+		t.Errorf("played %s, want the majority's d2d4", move)
+	}
+}
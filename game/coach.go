@@ -0,0 +1,134 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"hunsuChess/engine"
+)
+
+var (
+	ErrNoCoachBudget = errors.New("no coach consultations left")
+	ErrNotTurn       = errors.New("team of the player is not to move")
+	ErrNoAnalyzer    = errors.New("no engine or searcher to analyze with")
+)
+
+const (
+	// DefaultCoachBudget is the number of coach consultations of each team per game.
+	DefaultCoachBudget = 3
+	// Number of candidate moves the coach shows.
+	coachLines = 3
+)
+
+// CoachLeft returns the number of coach consultations the team has left in this game.
+func (game *Game) CoachLeft(team string) int {
+	used := game.WhiteCoachUsed
+	if team == "black" {
+		used = game.BlackCoachUsed
+	}
+	if used > game.CoachBudget {
+		return 0
+	}
+	return game.CoachBudget - used
+}
+
+// CoachVotes returns the number of players of the team to move who want to
+// consult the coach, and the size of the team.
+func (game *Game) CoachVotes() (int, int) {
+	players := game.currentPlayers()
+	votes := 0
+	for _, player := range players {
+		if player.CoachVote {
+			votes++
+		}
+	}
+	return votes, len(players)
+}
+
+// VoteCoach records the player's wish to consult the coach in this turn.
+// A consultation is spent once more than half of the team to move agrees.
+// It returns true if the vote spent a consultation.
+func (game *Game) VoteCoach(id string) (bool, error) {
+	team, ok := game.GetPlayerTeam(id)
+	if !ok {
+		return false, ErrNotJoined
+	}
+	player, ok := game.currentPlayers()[id]
+	if !ok {
+		return false, ErrNotTurn
+	}
+	game.coachMu.Lock()
+	defer game.coachMu.Unlock()
+	if game.Coached {
+		return false, nil
+	}
+	if game.CoachLeft(team) == 0 {
+		return false, ErrNoCoachBudget
+	}
+	player.CoachVote = true
+
+	votes, size := game.CoachVotes()
+	if votes*2 <= size {
+		return false, nil
+	}
+
+	game.Coached = true
+	if team == "white" {
+		game.WhiteCoachUsed++
+	} else {
+		game.BlackCoachUsed++
+	}
+	game.logEvent(fmt.Sprintf("%s이 %d표로 코치 상담을 사용했습니다. (남은 횟수 %d)", teamName(team), votes, game.CoachLeft(team)))
+	return true, nil
+}
+
+// Consult returns the coach's candidate moves for the current turn, best first.
+// The analysis runs once per consultation and is kept for the rest of the turn;
+// concurrent calls wait for the same analysis.
+func (game *Game) Consult(ctx context.Context) ([]engine.Line, error) {
+	game.coachMu.Lock()
+	defer game.coachMu.Unlock()
+
+	if !game.Coached {
+		return nil, ErrNoCoachBudget
+	}
+	if game.CoachAdvice != nil {
+		return game.CoachAdvice, nil
+	}
+
+	var analyzer engine.Analyzer
+	var limits engine.Limits
+	switch {
+	case game.Engine != nil:
+		analyzer = game.Engine
+		limits = engine.Limits{Depth: 18, MoveTime: 3 * time.Second}
+	case game.Searcher != nil:
+		analyzer = game.Searcher
+		limits = engine.Limits{Depth: game.Searcher.Depth, MoveTime: 10 * time.Second}
+	default:
+		return nil, ErrNoAnalyzer
+	}
+
+	lines, err := engine.MultiPV(ctx, analyzer, game.ChessGame.FEN(), coachLines, limits)
+	if err != nil {
+		return nil, err
+	}
+	game.CoachAdvice = lines
+	return lines, nil
+}
+
+// clearCoach forgets the consultation and the coach votes of the turn.
+func (game *Game) clearCoach() {
+	game.coachMu.Lock()
+	defer game.coachMu.Unlock()
+
+	game.Coached = false
+	game.CoachAdvice = nil
+	for _, players := range []map[string]*Player{game.WhitePlayers, game.BlackPlayers} {
+		for _, player := range players {
+			player.CoachVote = false
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"hunsuChess/engine"
+)
+
+func TestVoteCoach(t *testing.T) {
+	game := NewGame()
+	for _, id := range []string{"w1", "w2", "w3"} {
+		game.AddWhitePlayer(id)
+	}
+	game.AddBlackPlayer("b1")
+
+	if _, err := game.VoteCoach("nobody"); err != ErrNotJoined {
+		t.Errorf("vote of a stranger: %v, want ErrNotJoined", err)
+	}
+	// White is to move, the black player's vote must not count.
+	if _, err := game.VoteCoach("b1"); err != ErrNotTurn {
+		t.Errorf("vote off turn: %v, want ErrNotTurn", err)
+	}
+
+	if spent, err := game.VoteCoach("w1"); spent || err != nil {
+		t.Errorf("first vote: %v, %v, want no consultation yet", spent, err)
+	}
+	if spent, err := game.VoteCoach("w2"); !spent || err != nil {
+		t.Errorf("majority vote: %v, %v, want a consultation", spent, err)
+	}
+	if left := game.CoachLeft("white"); left != DefaultCoachBudget-1 {
+		t.Errorf("white has %d consultations left, want %d", left, DefaultCoachBudget-1)
+	}
+	if left := game.CoachLeft("black"); left != DefaultCoachBudget {
+		t.Errorf("black has %d consultations left, want %d", left, DefaultCoachBudget)
+	}
+}
+
+// countingAnalyzer answers every analysis with e4 after a short delay.
+type countingAnalyzer struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (a *countingAnalyzer) Analyze(ctx context.Context, fen string, limits engine.Limits) ([]engine.Line, error) {
+	a.mu.Lock()
+	a.calls++
+	a.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	return []engine.Line{{Move: "e2e4"}}, nil
+}
+
+func TestConsult(t *testing.T) {
+	game := newTestGame([]string{"w1"}, []string{"b1"})
+	if _, err := game.Consult(context.Background()); err != ErrNoCoachBudget {
+		t.Errorf("consultation without a vote: %v, want ErrNoCoachBudget", err)
+	}
+	if spent, err := game.VoteCoach("w1"); !spent || err != nil {
+		t.Fatalf("vote: %v, %v, want a consultation", spent, err)
+	}
+
+	game.Searcher = nil
+	if _, err := game.Consult(context.Background()); err != ErrNoAnalyzer {
+		t.Errorf("consultation without a searcher: %v, want ErrNoAnalyzer", err)
+	}
+
+	// Concurrent requests share one analysis.
+	analyzer := &countingAnalyzer{}
+	game.Engine = analyzer
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lines, err := game.Consult(context.Background())
+			if err != nil || len(lines) != 1 || lines[0].Move != "e2e4" {
+				t.Errorf("Consult = %v, %v, want e2e4", lines, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if analyzer.calls != 1 {
+		t.Errorf("analyzed %d times, want once", analyzer.calls)
+	}
+
+	nextTurn(t, game)
+	if game.Coached || game.CoachAdvice != nil {
+		t.Error("consultation carried over to the next turn")
+	}
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"hunsuChess/engine"
+)
+
+// newTestGame returns a game with a quick built-in engine and the players
+// joined, white first.
+func newTestGame(white []string, black []string) *Game {
+	game := NewGame()
+	game.Searcher = &engine.Searcher{Depth: 1}
+	for _, id := range white {
+		game.AddWhitePlayer(id)
+	}
+	for _, id := range black {
+		game.AddBlackPlayer(id)
+	}
+	return game
+}
+
+// nextTurn ends the turn and returns the move played.
+func nextTurn(t *testing.T, game *Game) string {
+	t.Helper()
+	if msg := game.Next(context.Background()); msg != "" {
+		t.Fatalf("game ended: %s", msg)
+	}
+	return game.RecentMove
+}
+
+func vote(t *testing.T, game *Game, id string, move string) {
+	t.Helper()
+	if err := game.VoteMove(id, move); err != nil {
+		t.Fatalf("vote %s of %s: %v", move, id, err)
+	}
+}
+
+func TestPickDeciderActiveOnly(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c"}, []string{"x"})
+	game.WhitePlayers["b"].LastActive = time.Now().Add(-2 * activeDuration)
+	game.WhitePlayers["c"].LastActive = time.Now().Add(-2 * activeDuration)
+
+	for i := 0; i < 20; i++ {
+		game.SetMode(DeciderMode)
+		if game.Decider != "a" {
+			t.Fatalf("decider %q, want the only active player a", game.Decider)
+		}
+	}
+	if !game.IsDecider("a") || game.IsDecider("b") {
+		t.Error("IsDecider does not match the decider")
+	}
+
+	// With nobody active, anyone of the team may decide.
+	game.WhitePlayers["a"].LastActive = time.Now().Add(-2 * activeDuration)
+	game.pickDecider()
+	if _, ok := game.WhitePlayers[game.Decider]; !ok {
+		t.Errorf("decider %q is not in the team to move", game.Decider)
+	}
+
+	game.SetMode(MajorityMode)
+	if game.Decider != "" || game.IsDecider("a") {
+		t.Errorf("decider %q in majority mode", game.Decider)
+	}
+}
+
+func TestDeciderMove(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c"}, []string{"x"})
+	game.SetMode(DeciderMode)
+	game.Decider = "a"
+	if msg := game.TurnMessage(); !strings.Contains(msg, "<@a>") {
+		t.Errorf("turn message %q does not ping the decider", msg)
+	}
+
+	// The decider's choice beats the majority.
+	vote(t, game, "a", "e4")
+	vote(t, game, "b", "d4")
+	vote(t, game, "c", "d4")
+	if move := nextTurn(t, game); move != "e2e4" {
+		t.Errorf("played %s, want the decider's e2e4", move)
+	}
+	if game.Decider != "x" {
+		t.Errorf("decider %q after the turn, want the black player x", game.Decider)
+	}
+}
+
+func TestDeciderFallsBackToVotes(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c"}, []string{"x"})
+	game.SetMode(DeciderMode)
+	game.Decider = "a"
+
+	// The decider did not choose, the majority decides.
+	vote(t, game, "b", "d4")
+	vote(t, game, "c", "d4")
+	if move := nextTurn(t, game); move != "d2d4" {
+		t.Errorf("played %s, want the majority's d2d4", move)
+	}
+}
+
+func TestNoEligibleDecider(t *testing.T) {
+	game := newTestGame(nil, []string{"x"})
+	game.SetMode(DeciderMode)
+	if game.Decider != "" {
+		t.Fatalf("decider %q for an empty team", game.Decider)
+	}
+	if msg := game.TurnMessage(); strings.Contains(msg, "결정권자") {
+		t.Errorf("turn message %q names a decider", msg)
+	}
+
+	// Players reached the team without a new draw, their votes decide.
+	game.WhitePlayers["a"] = &Player{LastActive: time.Now()}
+	game.WhitePlayers["b"] = &Player{LastActive: time.Now()}
+	vote(t, game, "a", "c4")
+	vote(t, game, "b", "c4")
+	if move := nextTurn(t, game); move != "c2c4" {
+		t.Errorf("played %s, want the voted c2c4", move)
+	}
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+)
+
+var (
+	ErrSelfDelegation  = errors.New("cannot delegate to yourself")
+	ErrNotTeammate     = errors.New("target is not a teammate")
+	ErrDelegationCycle = errors.New("delegation cycle")
+)
+
+// VoteCount is the weight of a move, split into the voters' own ballots
+// and the ballots delegated to them.
+type VoteCount struct {
+	Direct    int
+	Delegated int
+}
+
+func (c VoteCount) Total() int {
+	return c.Direct + c.Delegated
+}
+
+// Delegate makes the player follow the vote of a teammate.
+// An empty target removes the delegation.
+func (game *Game) Delegate(id string, target string) error {
+	players := game.teamOf(id)
+	if players == nil {
+		return ErrNotJoined
+	}
+
+	if target == "" {
+		players[id].Delegate = ""
+		return nil
+	}
+
+	if target == id {
+		return ErrSelfDelegation
+	}
+	if _, ok := players[target]; !ok {
+		return ErrNotTeammate
+	}
+
+	// Following the chain from the target must not lead back to the player.
+	visited := map[string]bool{}
+	for cur := target; cur != ""; cur = players[cur].Delegate {
+		if cur == id {
+			return ErrDelegationCycle
+		}
+		if visited[cur] {
+			break
+		}
+		visited[cur] = true
+		if _, ok := players[cur]; !ok {
+			break
+		}
+	}
+
+	players[id].Delegate = target
+	return nil
+}
+
+// DelegateOf returns the teammate the player delegates to, or an empty string.
+func (game *Game) DelegateOf(id string) string {
+	players := game.teamOf(id)
+	if players == nil {
+		return ""
+	}
+	return players[id].Delegate
+}
+
+// resolveDelegate follows the delegation chain of a player who did not vote
+// and returns the first delegate who did, or an empty string.
+func resolveDelegate(players map[string]*Player, id string) string {
+	visited := map[string]bool{id: true}
+	cur := players[id].Delegate
+	for cur != "" && !visited[cur] {
+		player, ok := players[cur]
+		if !ok {
+			return ""
+		}
+		if player.Move != "" {
+			return cur
+		}
+		visited[cur] = true
+		cur = player.Delegate
+	}
+	return ""
+}
+
+func (game *Game) teamOf(id string) map[string]*Player {
+	if _, ok := game.WhitePlayers[id]; ok {
+		return game.WhitePlayers
+	}
+	if _, ok := game.BlackPlayers[id]; ok {
+		return game.BlackPlayers
+	}
+	return nil
+}
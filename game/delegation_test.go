@@ -0,0 +1,81 @@
+package game
+
+import "testing"
+
+func TestDelegate(t *testing.T) {
+	tests := []struct {
+		name       string
+		delegation [][2]string // delegations made first, in order
+		id, target string
+		err        error
+	}{
+		{"to a teammate", nil, "a", "b", nil},
+		{"chain", [][2]string{{"b", "c"}}, "a", "b", nil},
+		{"to yourself", nil, "a", "a", ErrSelfDelegation},
+		{"to the other team", nil, "a", "x", ErrNotTeammate},
+		{"to a stranger", nil, "a", "nobody", ErrNotTeammate},
+		{"not joined", nil, "nobody", "a", ErrNotJoined},
+		{"direct cycle", [][2]string{{"b", "a"}}, "a", "b", ErrDelegationCycle},
+		{"long cycle", [][2]string{{"b", "c"}, {"c", "d"}, {"d", "a"}}, "a", "b", ErrDelegationCycle},
+		{"revoke", [][2]string{{"a", "b"}}, "a", "", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			game := newTestGame([]string{"a", "b", "c", "d"}, []string{"x"})
+			for _, d := range test.delegation {
+				if err := game.Delegate(d[0], d[1]); err != nil {
+					t.Fatalf("delegation %s to %s: %v", d[0], d[1], err)
+				}
+			}
+			before := game.DelegateOf(test.id)
+			err := game.Delegate(test.id, test.target)
+			if err != test.err {
+				t.Fatalf("Delegate(%q, %q) = %v, want %v", test.id, test.target, err, test.err)
+			}
+			want := test.target
+			if err != nil {
+				want = before
+			}
+			if got := game.DelegateOf(test.id); got != want {
+				t.Errorf("delegate of %s is %q, want %q", test.id, got, want)
+			}
+		})
+	}
+}
+
+func TestDelegatedVotes(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c", "d", "e"}, []string{"x"})
+	// a follows b, who follows c; d follows e, who does not vote.
+	for _, d := range [][2]string{{"a", "b"}, {"b", "c"}, {"d", "e"}} {
+		if err := game.Delegate(d[0], d[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vote(t, game, "c", "e4")
+
+	counts := game.GetVoteCounts()
+	if got := counts["e2e4"]; got != (VoteCount{Direct: 1, Delegated: 2}) {
+		t.Errorf("e2e4 counts %+v, want 1 direct and 2 delegated", got)
+	}
+	if len(counts) != 1 {
+		t.Errorf("counts %+v, want e2e4 only", counts)
+	}
+
+	// A delegate voting stops the chain at them.
+	vote(t, game, "b", "d4")
+	counts = game.GetVoteCounts()
+	if counts["d2d4"] != (VoteCount{Direct: 1, Delegated: 1}) || counts["e2e4"] != (VoteCount{Direct: 1}) {
+		t.Errorf("counts %+v, want a following b's d2d4", counts)
+	}
+
+	// Revoking the delegation takes the vote back.
+	if err := game.Delegate("a", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := game.GetVoteCounts()["d2d4"]; got != (VoteCount{Direct: 1}) {
+		t.Errorf("d2d4 counts %+v after revoking, want b's vote only", got)
+	}
+	if move := nextTurn(t, game); move != "d2d4" && move != "e2e4" {
+		t.Errorf("played %s, want one of the voted moves", move)
+	}
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+// Evaluation returns the evaluation of the current position from white's
+// point of view, for the evaluation bar. It is analyzed once per position;
+// concurrent calls wait for the same analysis.
+func (game *Game) Evaluation(ctx context.Context) (engine.Score, error) {
+	game.evalMu.Lock()
+	defer game.evalMu.Unlock()
+
+	fen := game.ChessGame.FEN()
+	if game.evalFEN == fen {
+		return game.evalScore, nil
+	}
+
+	var analyzer engine.Analyzer
+	var limits engine.Limits
+	switch {
+	case game.Engine != nil:
+		analyzer = game.Engine
+		limits = engine.Limits{Depth: 16, MoveTime: time.Second}
+	case game.Searcher != nil:
+		analyzer = game.Searcher
+		limits = engine.Limits{Depth: game.Searcher.Depth, MoveTime: time.Second}
+	default:
+		return engine.Score{}, ErrNoAnalyzer
+	}
+
+	score, err := engine.Evaluate(ctx, analyzer, fen, limits)
+	if err != nil {
+		return engine.Score{}, err
+	}
+	if game.ChessGame.Position().Turn() == chess.Black {
+		score = score.Negate()
+	}
+	game.evalFEN, game.evalScore = fen, score
+	return score, nil
+}
+
+// SetEvalBar turns the evaluation bar of /game on or off.
+func (game *Game) SetEvalBar(enabled bool) {
+	game.EvalBar = enabled
+}
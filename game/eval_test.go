@@ -0,0 +1,45 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"hunsuChess/engine"
+)
+
+func TestEvaluation(t *testing.T) {
+	game := NewGame()
+	game.Searcher = nil
+	if _, err := game.Evaluation(context.Background()); err != ErrNoAnalyzer {
+		t.Errorf("evaluation without a searcher: %v, want ErrNoAnalyzer", err)
+	}
+
+	game.Searcher = &engine.Searcher{Depth: 2}
+	if err := game.ChessGame.MoveStr("e4"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	scores := make([]engine.Score, 4)
+	for i := range scores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			score, err := game.Evaluation(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			scores[i] = score
+		}()
+	}
+	wg.Wait()
+	for _, score := range scores[1:] {
+		if score != scores[0] {
+			t.Errorf("scores %v differ", scores)
+		}
+	}
+	// After 1. e4 white is better, although black is to move.
+	if scores[0].Value() <= 0 {
+		t.Errorf("1. e4 evaluated %v, want white ahead", scores[0])
+	}
+}
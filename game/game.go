@@ -1,16 +1,49 @@
 package game
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"hunsuChess/commentary"
+	"hunsuChess/engine"
+
 	"github.com/notnil/chess"
 )
 
+// DecisionMode decides how the move of a turn is chosen from the team's votes.
+type DecisionMode int
+
+const (
+	// MajorityMode plays the move with the most votes.
+	MajorityMode DecisionMode = iota
+	// DeciderMode lets a randomly chosen member decide, other votes are advisory.
+	DeciderMode
+)
+
+var (
+	ErrGameOver    = errors.New("game is over")
+	ErrNotJoined   = errors.New("not joined game")
+	ErrInvalidMove = errors.New("invalid move")
+	ErrVetoed      = errors.New("move is vetoed")
+)
+
+// A player is active if they joined or voted within this duration.
+const activeDuration = 3 * 24 * time.Hour
+
+// DefaultSearchTime is how long an engine searches for a move it plays for a
+// team, unless the Searcher sets another time. Every engine move gets its own
+// deadline of the search time plus searchGrace for the engine to answer.
+const (
+	DefaultSearchTime = 3 * time.Second
+	searchGrace       = 2 * time.Second
+)
+
 type Game struct {
 	ChessGame    *chess.Game
 	WhitePlayers map[string]*Player
@@ -21,15 +54,59 @@ type Game struct {
 	NextTime time.Time
 
 	RecentMove string
+
+	Mode    DecisionMode
+	Decider string // player who decides the move of this turn in DeciderMode
+
+	// Channels where each team's announcements are posted.
+	WhiteChannelID string
+	BlackChannelID string
+
+	WhiteCaptain string
+	BlackCaptain string
+	Vetoed       string // move vetoed by the captain in this turn
+
+	History    []TurnRecord
+	turnEvents []string
+
+	Commentary *commentary.Locale // language of the move commentary in turn announcements, nil for none
+
+	Engine   engine.Analyzer  // external engine for analysis, nil if not configured
+	Searcher *engine.Searcher // built-in engine, plays for teams that did not vote
+
+	BotColor    chess.Color // color played by the engine, NoColor if both teams are players
+	BotStrength int         // strength of the engine opponent
+
+	Runoff     bool     // whether turns have a proposal phase followed by a runoff
+	RunoffSize int      // number of nominations that go to the runoff
+	Candidates []string // moves votable in the runoff, nil during the proposal phase
+
+	CoachBudget    int           // coach consultations of each team per game
+	WhiteCoachUsed int           // consultations spent by white in this game
+	BlackCoachUsed int           // consultations spent by black in this game
+	Coached        bool          // whether the team to move consulted the coach in this turn
+	CoachAdvice    []engine.Line // coach's candidate moves of this turn, nil until analyzed
+	coachMu        sync.Mutex    // guards Coached and CoachAdvice
+
+	EvalBar   bool         // whether /game shows the evaluation of the position
+	evalMu    sync.Mutex   // guards evalFEN and evalScore
+	evalFEN   string       // position of evalScore
+	evalScore engine.Score // evaluation of evalFEN from white's point of view
 }
 
 type Player struct {
-	Move string
+	Move        string
+	Delegate    string          // teammate whose vote this player follows when not voting
+	CaptainVote string          // candidate this player supports as captain
+	CoachVote   bool            // whether this player wants to consult the coach in this turn
+	Reason      string          // short argument given for Move
+	Likes       map[string]bool // teammates who liked Reason
+	LastActive  time.Time
 }
 
 type moveVote struct {
 	move  string
-	count int
+	count VoteCount
 }
 
 func NewGame() *Game {
@@ -39,6 +116,11 @@ func NewGame() *Game {
 		WhitePlayers: make(map[string]*Player),
 		BlackPlayers: make(map[string]*Player),
 		GameOver:     false,
+		RunoffSize:   DefaultRunoffSize,
+		CoachBudget:  DefaultCoachBudget,
+		EvalBar:      true,
+		Commentary:   commentary.Korean,
+		Searcher:     &engine.Searcher{Depth: 4, MoveTime: DefaultSearchTime},
 	}
 }
 
@@ -47,12 +129,117 @@ func (game *Game) Reset() {
 	game.Turn = false
 	game.RecentMove = ""
 	game.GameOver = false
+	game.Vetoed = ""
+	game.Candidates = nil
+	game.History = nil
+	game.turnEvents = nil
+	game.WhiteCoachUsed = 0
+	game.BlackCoachUsed = 0
+	game.clearCoach()
 	for _, p := range game.WhitePlayers {
 		p.Move = ""
+		p.clearReason()
 	}
 	for _, p := range game.BlackPlayers {
 		p.Move = ""
+		p.clearReason()
+	}
+
+	if game.isBotTurn() {
+		game.playBotMove(context.Background())
+		game.Turn = !game.Turn
+	}
+	game.pickDecider()
+}
+
+// SetMode changes the decision mode, choosing a decider for the current turn if needed.
+func (game *Game) SetMode(mode DecisionMode) {
+	game.Mode = mode
+	game.pickDecider()
+}
+
+// pickDecider chooses a random active member of the team to move as the decider.
+// Members who have not been active recently are only chosen if nobody else is.
+func (game *Game) pickDecider() {
+	game.Decider = ""
+	if game.Mode != DeciderMode {
+		return
+	}
+
+	players := game.currentPlayers()
+	var active, all []string
+	for id, player := range players {
+		all = append(all, id)
+		if time.Since(player.LastActive) < activeDuration {
+			active = append(active, id)
+		}
 	}
+
+	candidates := active
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	sort.Strings(candidates)
+	game.Decider = candidates[rand.Intn(len(candidates))]
+}
+
+// IsDecider reports whether the player decides the move of the current turn.
+func (game *Game) IsDecider(id string) bool {
+	return game.Mode == DeciderMode && game.Decider != "" && game.Decider == id
+}
+
+func (game *Game) currentPlayers() map[string]*Player {
+	if !game.Turn {
+		return game.WhitePlayers
+	}
+	return game.BlackPlayers
+}
+
+// SetTeamChannel remembers the channel used for the team's announcements.
+func (game *Game) SetTeamChannel(team string, channelID string) {
+	if team == "white" {
+		game.WhiteChannelID = channelID
+	} else {
+		game.BlackChannelID = channelID
+	}
+}
+
+// TurnChannelID returns the announcement channel of the team to move.
+func (game *Game) TurnChannelID() string {
+	if !game.Turn {
+		return game.WhiteChannelID
+	}
+	return game.BlackChannelID
+}
+
+// TurnMessage describes the start of the current turn, pinging the decider if there is one.
+func (game *Game) TurnMessage() string {
+	var turn string
+	if !game.Turn {
+		turn = "백"
+	} else {
+		turn = "흑"
+	}
+
+	msg := fmt.Sprintf("%s팀 차례입니다.", turn)
+	if game.RecentMove != "" {
+		msg = fmt.Sprintf("상대 팀이 **%s**를 두었습니다. %s", game.RecentMove, msg)
+		if game.Commentary != nil {
+			if comment := commentary.Comment(game.ChessGame, game.Commentary); comment != "" {
+				msg = fmt.Sprintf("%s\n> %s", msg, comment)
+			}
+		}
+		if n := len(game.History); n > 0 && len(game.History[n-1].Reasons) > 0 {
+			msg = fmt.Sprintf("%s\n%s", msg, formatReasons(game.History[n-1].Reasons))
+		}
+	}
+	if game.Mode == DeciderMode && game.Decider != "" {
+		msg += fmt.Sprintf("\n<@%s>님이 이번 턴의 결정권자입니다. 기한까지 결정하지 않으면 다수결로 수가 정해집니다.", game.Decider)
+	}
+	return msg
 }
 
 func (game *Game) IsGameOver() bool {
@@ -63,7 +250,7 @@ func (game *Game) VoteMove(id string, chat string) error {
 	var players map[string]*Player
 
 	if game.GameOver {
-		return errors.New("game is over")
+		return ErrGameOver
 	}
 
 	if !game.Turn {
@@ -73,7 +260,13 @@ func (game *Game) VoteMove(id string, chat string) error {
 	}
 
 	if _, ok := players[id]; !ok {
-		return errors.New("not joined game")
+		return ErrNotJoined
+	}
+
+	if move := game.findMove(chat); move != nil && move.String() == game.Vetoed {
+		return ErrVetoed
+	} else if move != nil && game.InRunoff() && !game.isCandidate(move.String()) {
+		return ErrNotCandidate
 	}
 
 	// Try to match SAN
@@ -82,6 +275,8 @@ func (game *Game) VoteMove(id string, chat string) error {
 		if chat == san {
 			player := players[id]
 			player.Move = move.String() // Store as UCI
+			player.clearReason()
+			player.LastActive = time.Now()
 			return nil
 		}
 	}
@@ -91,11 +286,13 @@ func (game *Game) VoteMove(id string, chat string) error {
 		if chat == move.String() {
 			player := players[id]
 			player.Move = chat
+			player.clearReason()
+			player.LastActive = time.Now()
 			return nil
 		}
 	}
 
-	return errors.New("invalid move")
+	return ErrInvalidMove
 }
 
 func (game *Game) GetVotes() []string {
@@ -118,10 +315,14 @@ func (game *Game) GetVotes() []string {
 	return moves
 }
 
-func (game *Game) Next() string {
+// Next plays the move of the team to move and passes the turn. If the team
+// did not vote, or in bot mode, an engine moves for a team; each such move has
+// its own deadline, and ctx only cancels them.
+func (game *Game) Next(ctx context.Context) string {
 	var players map[string]*Player
 	movesCount := make(map[string]int)
 	var maxCount int = 0
+	var deciderMove string
 
 	if !game.Turn {
 		players = game.WhitePlayers
@@ -129,9 +330,27 @@ func (game *Game) Next() string {
 		players = game.BlackPlayers
 	}
 
+	if decider, ok := players[game.Decider]; ok && game.Mode == DeciderMode {
+		deciderMove = decider.Move
+	}
+
+	for m, c := range game.GetVoteCounts() {
+		movesCount[m] = c.Total()
+	}
+	votes := make(map[string]string)
+	for id, player := range players {
+		if player.Move != "" {
+			votes[id] = player.Move
+		}
+	}
+	captainMove := votes[game.TurnCaptain()]
+	reasons := make(map[string][]Reason)
+	for _, reason := range game.Reasons() {
+		reasons[reason.Move] = append(reasons[reason.Move], reason)
+	}
 	for _, player := range players {
-		movesCount[player.Move] += 1
 		player.Move = ""
+		player.clearReason()
 	}
 
 	for m, c := range movesCount {
@@ -150,65 +369,166 @@ func (game *Game) Next() string {
 		}
 	}
 
-	if len(tiedMoves) > 0 {
+	if deciderMove != "" {
+		game.RecentMove = deciderMove
+	} else if len(tiedMoves) > 0 {
+		sort.Strings(tiedMoves)
 		game.RecentMove = tiedMoves[rand.Intn(len(tiedMoves))]
-	} else {
-		validMoves := game.ChessGame.ValidMoves()
-		if len(validMoves) > 0 {
-			game.RecentMove = validMoves[rand.Intn(len(validMoves))].String()
-		} else {
-			game.RecentMove = ""
+		if len(tiedMoves) > 1 && captainMove != "" {
+			for _, m := range tiedMoves {
+				if m == captainMove {
+					game.RecentMove = captainMove
+					game.logEvent(fmt.Sprintf("동점인 %d개의 수 중 주장 <@%s>님의 선택 **%s**로 결정되었습니다.", len(tiedMoves), game.TurnCaptain(), captainMove))
+				}
+			}
 		}
+	} else {
+		game.RecentMove = game.fallbackMove(ctx)
 	}
 
 	if game.RecentMove != "" {
 		m, _ := chess.UCINotation{}.Decode(game.ChessGame.Position(), game.RecentMove)
 		game.ChessGame.Move(m)
 	}
+	game.recordTurn(votes, reasons[game.RecentMove])
+	game.Vetoed = ""
+	game.Candidates = nil
+	game.clearCoach()
 
-	if outcome := game.ChessGame.Outcome(); outcome != chess.NoOutcome {
-		var result string
-		switch outcome {
-		case chess.WhiteWon:
-			result = "백팀이 승리했습니다!"
-		case chess.BlackWon:
-			result = "흑팀이 승리했습니다!"
-		case chess.Draw:
-			result = "무승부입니다!"
-		}
-		method := game.ChessGame.Method()
-		msg := fmt.Sprintf("게임 종료! %s (%s)", result, method.String())
-		game.GameOver = true
+	if msg := game.checkOutcome(); msg != "" {
 		return msg
 	}
-
 	game.Turn = !game.Turn
+
+	// In bot mode the engine replies right away, so the community moves every turn.
+	if game.isBotTurn() {
+		game.playBotMove(ctx)
+		if msg := game.checkOutcome(); msg != "" {
+			return msg
+		}
+		game.Turn = !game.Turn
+	}
+
+	game.pickDecider()
 	return ""
 }
 
-func (game *Game) GetVoteCounts() map[string]int {
-	var players map[string]*Player
+// checkOutcome ends the game if the last move finished it and returns the result message.
+func (game *Game) checkOutcome() string {
+	outcome := game.ChessGame.Outcome()
+	if outcome == chess.NoOutcome {
+		return ""
+	}
 
-	if !game.Turn {
-		players = game.WhitePlayers
-	} else {
-		players = game.BlackPlayers
+	var result string
+	switch outcome {
+	case chess.WhiteWon:
+		result = "백팀이 승리했습니다!"
+	case chess.BlackWon:
+		result = "흑팀이 승리했습니다!"
+	case chess.Draw:
+		result = "무승부입니다!"
 	}
+	method := game.ChessGame.Method()
+	game.GameOver = true
+	return fmt.Sprintf("게임 종료! %s (%s)", result, method.String())
+}
 
-	counts := make(map[string]int)
-	for _, player := range players {
+// fallbackMove chooses the move of a team that did not vote with the built-in
+// engine. A random move is played if the engine cannot find one in time.
+func (game *Game) fallbackMove(ctx context.Context) string {
+	validMoves := game.ChessGame.ValidMoves()
+	if len(validMoves) == 0 {
+		return ""
+	}
+
+	if game.Searcher != nil {
+		ctx, cancel := game.searchContext(ctx)
+		defer cancel()
+		move, err := engine.BestMove(ctx, game.Searcher, game.ChessGame.FEN(), engine.Limits{MoveTime: game.searchTime()})
+		if err == nil {
+			return move
+		}
+		fmt.Printf("Built-in engine failed, playing a random move: %v\n", err)
+	}
+	return validMoves[rand.Intn(len(validMoves))].String()
+}
+
+// searchTime returns how long an engine searches for a move it plays for a team.
+func (game *Game) searchTime() time.Duration {
+	if game.Searcher != nil && game.Searcher.MoveTime > 0 {
+		return game.Searcher.MoveTime
+	}
+	return DefaultSearchTime
+}
+
+// searchContext bounds one engine move: the search time and the time the
+// engine takes to answer after it.
+func (game *Game) searchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, game.searchTime()+searchGrace)
+}
+
+// GetVoteCounts tallies the votes of the team to move.
+// Players who did not vote add their weight to the move of their delegate.
+func (game *Game) GetVoteCounts() map[string]VoteCount {
+	players := game.currentPlayers()
+
+	counts := make(map[string]VoteCount)
+	for id, player := range players {
 		if player.Move != "" {
-			counts[player.Move]++
+			c := counts[player.Move]
+			c.Direct++
+			counts[player.Move] = c
+			continue
+		}
+		if delegate := resolveDelegate(players, id); delegate != "" {
+			move := players[delegate].Move
+			c := counts[move]
+			c.Delegated++
+			counts[move] = c
 		}
 	}
 	return counts
 }
 
+// LeadingMoves returns the moves that would be played if the turn ended now:
+// the decider's choice, or the most voted moves unless the captain's vote breaks the tie.
+func (game *Game) LeadingMoves() []string {
+	players := game.currentPlayers()
+	if decider, ok := players[game.Decider]; ok && game.Mode == DeciderMode && decider.Move != "" {
+		return []string{decider.Move}
+	}
+
+	var leading []string
+	maxCount := 0
+	for m, c := range game.GetVoteCounts() {
+		switch {
+		case m == "" || c.Total() < maxCount:
+		case c.Total() > maxCount:
+			maxCount = c.Total()
+			leading = []string{m}
+		default:
+			leading = append(leading, m)
+		}
+	}
+	if len(leading) > 1 {
+		if captain, ok := players[game.TurnCaptain()]; ok {
+			for _, m := range leading {
+				if m == captain.Move {
+					return []string{m}
+				}
+			}
+		}
+	}
+	sort.Strings(leading)
+	return leading
+}
+
 func (game *Game) GetTopNVotes(n int) string {
 	counts := game.GetVoteCounts()
 	var totalVotes int
 	for _, count := range counts {
-		totalVotes += count
+		totalVotes += count.Total()
 	}
 
 	if totalVotes == 0 {
@@ -221,7 +541,7 @@ func (game *Game) GetTopNVotes(n int) string {
 	}
 
 	sort.Slice(sortedVotes, func(i, j int) bool {
-		return sortedVotes[i].count > sortedVotes[j].count
+		return sortedVotes[i].count.Total() > sortedVotes[j].count.Total()
 	})
 
 	var topVotes []string
@@ -234,17 +554,40 @@ func (game *Game) GetTopNVotes(n int) string {
 			moveStr = chess.AlgebraicNotation{}.Encode(game.ChessGame.Position(), move)
 		}
 
-		percentage := float64(sortedVotes[i].count) / float64(totalVotes) * 100
-		topVotes = append(topVotes, fmt.Sprintf("%s: %.2f%% (%d표)", moveStr, percentage, sortedVotes[i].count))
+		count := sortedVotes[i].count
+		percentage := float64(count.Total()) / float64(totalVotes) * 100
+		var line string
+		if count.Delegated > 0 {
+			line = fmt.Sprintf("%s: %.2f%% (직접 %d표 + 위임 %d표)", moveStr, percentage, count.Direct, count.Delegated)
+		} else {
+			line = fmt.Sprintf("%s: %.2f%% (%d표)", moveStr, percentage, count.Direct)
+		}
+		if reasons := game.reasonsFor(sortedVotes[i].move, 2); len(reasons) > 0 {
+			line += "\n" + formatReasons(reasons)
+		}
+		topVotes = append(topVotes, line)
 	}
 
 	if len(topVotes) == 0 {
 		return "아직 투표가 없습니다."
 	}
 
+	if game.InRunoff() {
+		return "결선 투표 현황:\n" + strings.Join(topVotes, "\n")
+	}
 	return "현재 투표 현황:\n" + strings.Join(topVotes, "\n")
 }
 
+// SAN converts a UCI move of the current position to algebraic notation.
+// The move is returned unchanged if it cannot be decoded.
+func (game *Game) SAN(uci string) string {
+	move, err := chess.UCINotation{}.Decode(game.ChessGame.Position(), uci)
+	if err != nil {
+		return uci
+	}
+	return chess.AlgebraicNotation{}.Encode(game.ChessGame.Position(), move)
+}
+
 func (game *Game) GetPlayerTeam(id string) (string, bool) {
 	if _, ok := game.WhitePlayers[id]; ok {
 		return "white", true
@@ -259,12 +602,24 @@ func (game *Game) AddWhitePlayer(id string) {
 	if _, ok := game.BlackPlayers[id]; ok {
 		delete(game.BlackPlayers, id)
 	}
-	game.WhitePlayers[id] = &Player{}
+	if game.BlackCaptain == id {
+		game.BlackCaptain = ""
+	}
+	game.WhitePlayers[id] = &Player{LastActive: time.Now()}
+	if game.Decider == "" {
+		game.pickDecider()
+	}
 }
 
 func (game *Game) AddBlackPlayer(id string) {
 	if _, ok := game.WhitePlayers[id]; ok {
 		delete(game.WhitePlayers, id)
 	}
-	game.BlackPlayers[id] = &Player{}
+	if game.WhiteCaptain == id {
+		game.WhiteCaptain = ""
+	}
+	game.BlackPlayers[id] = &Player{LastActive: time.Now()}
+	if game.Decider == "" {
+		game.pickDecider()
+	}
 }
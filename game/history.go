@@ -0,0 +1,86 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TurnRecord is the result of a resolved turn.
+type TurnRecord struct {
+	Ply     int
+	Team    string
+	Move    string            // UCI, empty if no move could be played
+	Votes   map[string]string // player ID to the move they voted for
+	Events  []string          // notable actions taken during the turn
+	Reasons []Reason          // most liked reasons given for the played move
+}
+
+// logEvent records an action of the current turn in the history.
+func (game *Game) logEvent(event string) {
+	game.turnEvents = append(game.turnEvents, event)
+}
+
+// recordTurn stores the resolved turn in the history. Votes must be
+// collected before they are cleared.
+func (game *Game) recordTurn(votes map[string]string, reasons []Reason) {
+	if len(reasons) > 2 {
+		reasons = reasons[:2]
+	}
+	team := "white"
+	if game.Turn {
+		team = "black"
+	}
+	game.History = append(game.History, TurnRecord{
+		Ply:     len(game.History) + 1,
+		Team:    team,
+		Move:    game.RecentMove,
+		Votes:   votes,
+		Events:  game.turnEvents,
+		Reasons: reasons,
+	})
+	game.turnEvents = nil
+}
+
+// GetHistory describes the last n turns, including the actions taken in them.
+func (game *Game) GetHistory(n int) string {
+	if len(game.History) == 0 && len(game.turnEvents) == 0 {
+		return "아직 진행된 턴이 없습니다."
+	}
+
+	var lines []string
+	start := len(game.History) - n
+	if start < 0 {
+		start = 0
+	}
+	for _, record := range game.History[start:] {
+		move := record.Move
+		if move == "" {
+			move = "-"
+		}
+		lines = append(lines, fmt.Sprintf("**%d. %s** %s (%d명 투표)", record.Ply, teamName(record.Team), move, len(record.Votes)))
+		if len(record.Reasons) > 0 {
+			lines = append(lines, formatReasons(record.Reasons))
+		}
+		for _, event := range record.Events {
+			lines = append(lines, "　• "+event)
+		}
+	}
+
+	if len(game.turnEvents) > 0 {
+		lines = append(lines, "**진행 중인 턴**")
+		for _, event := range game.turnEvents {
+			lines = append(lines, "　• "+event)
+		}
+	}
+
+	return "턴 기록:\n" + strings.Join(lines, "\n")
+}
+
+// Votes returns the votes of every recorded ply, in order.
+func (game *Game) Votes() []map[string]string {
+	votes := make([]map[string]string, len(game.History))
+	for i, record := range game.History {
+		votes[i] = record.Votes
+	}
+	return votes
+}
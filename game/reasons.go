@@ -0,0 +1,101 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoReason  = errors.New("no reason to like")
+	ErrOwnReason = errors.New("cannot like your own reason")
+)
+
+// Reason is a short argument a player attached to their vote.
+type Reason struct {
+	Author string
+	Move   string
+	Text   string
+	Likes  int
+}
+
+// VoteMoveWithReason votes for a move and attaches a one-line argument to the vote.
+func (game *Game) VoteMoveWithReason(id string, moveStr string, reason string) error {
+	if err := game.VoteMove(id, moveStr); err != nil {
+		return err
+	}
+	reason = strings.TrimSpace(strings.ReplaceAll(reason, "\n", " "))
+	game.currentPlayers()[id].Reason = reason
+	return nil
+}
+
+// LikeReason adds the player's like to the reason of a teammate's vote.
+func (game *Game) LikeReason(id string, author string) error {
+	players := game.currentPlayers()
+	if _, ok := players[id]; !ok {
+		return ErrNotJoined
+	}
+	player, ok := players[author]
+	if !ok || player.Reason == "" {
+		return ErrNoReason
+	}
+	if id == author {
+		return ErrOwnReason
+	}
+	if player.Likes == nil {
+		player.Likes = make(map[string]bool)
+	}
+	player.Likes[id] = true
+	return nil
+}
+
+// Reasons returns the arguments of the team to move, most liked first.
+func (game *Game) Reasons() []Reason {
+	var reasons []Reason
+	for id, player := range game.currentPlayers() {
+		if player.Move == "" || player.Reason == "" {
+			continue
+		}
+		reasons = append(reasons, Reason{
+			Author: id,
+			Move:   player.Move,
+			Text:   player.Reason,
+			Likes:  len(player.Likes),
+		})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Likes != reasons[j].Likes {
+			return reasons[i].Likes > reasons[j].Likes
+		}
+		return reasons[i].Author < reasons[j].Author
+	})
+	return reasons
+}
+
+// reasonsFor returns up to n of the most liked reasons given for the move.
+func (game *Game) reasonsFor(move string, n int) []Reason {
+	var reasons []Reason
+	for _, reason := range game.Reasons() {
+		if len(reasons) == n {
+			break
+		}
+		if reason.Move == move {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+func formatReasons(reasons []Reason) string {
+	var lines []string
+	for _, reason := range reasons {
+		lines = append(lines, fmt.Sprintf("　💬 \"%s\" (👍 %d)", reason.Text, reason.Likes))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *Player) clearReason() {
+	p.Reason = ""
+	p.Likes = nil
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReasons(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c"}, []string{"x"})
+	if err := game.VoteMoveWithReason("a", "e4", " takes\nthe center "); err != nil {
+		t.Fatal(err)
+	}
+	if err := game.VoteMoveWithReason("b", "d4", "solid"); err != nil {
+		t.Fatal(err)
+	}
+	if err := game.VoteMoveWithReason("c", "Qh5", "bad"); err != ErrInvalidMove {
+		t.Errorf("reason for an illegal move: %v, want ErrInvalidMove", err)
+	}
+	if reason := game.WhitePlayers["a"].Reason; reason != "takes the center" {
+		t.Errorf("reason %q, want it on one line", reason)
+	}
+
+	tests := []struct {
+		id, author string
+		err        error
+	}{
+		{"c", "b", nil},
+		{"a", "b", nil},
+		{"c", "b", nil}, // a second like by the same player does not count
+		{"b", "b", ErrOwnReason},
+		{"a", "c", ErrNoReason},
+		{"x", "a", ErrNotJoined},
+	}
+	for _, test := range tests {
+		if err := game.LikeReason(test.id, test.author); err != test.err {
+			t.Errorf("like of %s for %s: %v, want %v", test.id, test.author, err, test.err)
+		}
+	}
+
+	reasons := game.Reasons()
+	if len(reasons) != 2 || reasons[0].Author != "b" || reasons[0].Likes != 2 || reasons[1].Author != "a" {
+		t.Fatalf("reasons %+v, want b's with 2 likes first", reasons)
+	}
+
+	vote(t, game, "c", "d4")
+	if move := nextTurn(t, game); move != "d2d4" {
+		t.Fatalf("played %s, want d2d4", move)
+	}
+	record := game.History[len(game.History)-1]
+	if len(record.Reasons) != 1 || record.Reasons[0].Text != "solid" {
+		t.Errorf("recorded reasons %+v, want the reason for d4 only", record.Reasons)
+	}
+	if history := game.GetHistory(1); !strings.Contains(history, "\"solid\" (👍 2)") {
+		t.Errorf("history %q does not show the reason", history)
+	}
+	if game.WhitePlayers["b"].Reason != "" || game.WhitePlayers["b"].Likes != nil {
+		t.Error("reason carried over to the next turn")
+	}
+}
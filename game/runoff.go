@@ -0,0 +1,101 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNotCandidate  = errors.New("move is not a runoff candidate")
+	ErrRunoffStarted = errors.New("proposal phase is over")
+)
+
+// DefaultRunoffSize is the number of nominations that go to the runoff.
+const DefaultRunoffSize = 4
+
+// SetRunoff enables or disables the proposal phase followed by a runoff
+// between the top size nominations.
+func (game *Game) SetRunoff(enabled bool, size int) {
+	if size <= 0 {
+		size = DefaultRunoffSize
+	}
+	game.Runoff = enabled
+	game.RunoffSize = size
+	if !enabled {
+		game.Candidates = nil
+	}
+}
+
+// InRunoff reports whether the turn is in its final window, where only
+// the candidates can be voted for.
+func (game *Game) InRunoff() bool {
+	return game.Candidates != nil
+}
+
+// Propose nominates a move with a short reason during the proposal phase.
+// The nomination also counts as the player's vote.
+func (game *Game) Propose(id string, moveStr string, reason string) error {
+	if game.InRunoff() {
+		return ErrRunoffStarted
+	}
+	return game.VoteMoveWithReason(id, moveStr, reason)
+}
+
+// StartRunoff closes the proposal phase and keeps the top nominations as
+// the only votable moves. Votes for other moves are discarded.
+// It returns false if the runoff is disabled or nothing was nominated.
+func (game *Game) StartRunoff() bool {
+	if !game.Runoff || game.InRunoff() || game.GameOver {
+		return false
+	}
+
+	counts := game.GetVoteCounts()
+	if len(counts) == 0 {
+		return false
+	}
+
+	moves := make([]string, 0, len(counts))
+	for move := range counts {
+		moves = append(moves, move)
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		if counts[moves[i]].Total() != counts[moves[j]].Total() {
+			return counts[moves[i]].Total() > counts[moves[j]].Total()
+		}
+		return moves[i] < moves[j]
+	})
+	if len(moves) > game.RunoffSize {
+		moves = moves[:game.RunoffSize]
+	}
+	game.Candidates = moves
+
+	for _, player := range game.currentPlayers() {
+		if !game.isCandidate(player.Move) {
+			player.Move = ""
+			player.clearReason()
+		}
+	}
+
+	game.logEvent(fmt.Sprintf("결선 투표 후보: %s", strings.Join(game.CandidateSANs(), ", ")))
+	return true
+}
+
+// CandidateSANs returns the runoff candidates in algebraic notation.
+func (game *Game) CandidateSANs() []string {
+	sans := make([]string, 0, len(game.Candidates))
+	for _, candidate := range game.Candidates {
+		sans = append(sans, game.SAN(candidate))
+	}
+	return sans
+}
+
+func (game *Game) isCandidate(move string) bool {
+	for _, candidate := range game.Candidates {
+		if candidate == move {
+			return true
+		}
+	}
+	return false
+}
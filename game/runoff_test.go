@@ -0,0 +1,55 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStartRunoff(t *testing.T) {
+	game := newTestGame([]string{"a", "b", "c", "d", "e"}, []string{"x"})
+	if game.StartRunoff() {
+		t.Fatal("runoff started while disabled")
+	}
+	game.SetRunoff(true, 2)
+	if game.StartRunoff() {
+		t.Fatal("runoff started without nominations")
+	}
+
+	for _, p := range [][3]string{{"a", "e4", "center"}, {"b", "e4", "center"}, {"c", "d4", "queen's pawn"}, {"d", "c4", "english"}} {
+		if err := game.Propose(p[0], p[1], p[2]); err != nil {
+			t.Fatalf("proposal %s of %s: %v", p[1], p[0], err)
+		}
+	}
+	if !game.StartRunoff() || !game.InRunoff() {
+		t.Fatal("runoff did not start")
+	}
+	// d4 and c4 tie at the cutoff, the order of the moves breaks it.
+	if want := []string{"e2e4", "c2c4"}; !reflect.DeepEqual(game.Candidates, want) {
+		t.Errorf("candidates %v, want %v", game.Candidates, want)
+	}
+	if want := []string{"e4", "c4"}; !reflect.DeepEqual(game.CandidateSANs(), want) {
+		t.Errorf("candidate SANs %v, want %v", game.CandidateSANs(), want)
+	}
+	if game.WhitePlayers["c"].Move != "" {
+		t.Error("vote for a dropped nomination was kept")
+	}
+	if game.StartRunoff() {
+		t.Error("runoff started twice")
+	}
+
+	if err := game.Propose("e", "Nf3", "late"); err != ErrRunoffStarted {
+		t.Errorf("proposal in the runoff: %v, want ErrRunoffStarted", err)
+	}
+	if err := game.VoteMove("c", "d4"); err != ErrNotCandidate {
+		t.Errorf("vote for a non-candidate: %v, want ErrNotCandidate", err)
+	}
+	vote(t, game, "c", "c4")
+	vote(t, game, "e", "c2c4")
+
+	if move := nextTurn(t, game); move != "c2c4" {
+		t.Errorf("played %s, want the runoff winner c2c4", move)
+	}
+	if game.InRunoff() {
+		t.Error("runoff carried over to the next turn")
+	}
+}
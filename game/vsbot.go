@@ -0,0 +1,105 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+const (
+	MinBotStrength = 1
+	MaxBotStrength = 5
+)
+
+// StartBotGame starts a new game where the community plays one color through
+// votes and the engine plays the other. A NoColor community ends the bot mode.
+// Players on the engine's side move to the community's side.
+func (game *Game) StartBotGame(community chess.Color, strength int) {
+	switch {
+	case community == chess.NoColor:
+		game.BotColor = chess.NoColor
+	case community == chess.White:
+		game.BotColor = chess.Black
+		for id := range game.BlackPlayers {
+			game.AddWhitePlayer(id)
+		}
+		if game.WhiteChannelID == "" {
+			game.WhiteChannelID = game.BlackChannelID
+		}
+	default:
+		game.BotColor = chess.White
+		for id := range game.WhitePlayers {
+			game.AddBlackPlayer(id)
+		}
+		if game.BlackChannelID == "" {
+			game.BlackChannelID = game.WhiteChannelID
+		}
+	}
+
+	if strength < MinBotStrength {
+		strength = MinBotStrength
+	} else if strength > MaxBotStrength {
+		strength = MaxBotStrength
+	}
+	game.BotStrength = strength
+
+	game.Reset()
+}
+
+// CommunityTeam returns the team played by the community in bot mode, or an empty string.
+func (game *Game) CommunityTeam() string {
+	switch game.BotColor {
+	case chess.White:
+		return "black"
+	case chess.Black:
+		return "white"
+	}
+	return ""
+}
+
+func (game *Game) isBotTurn() bool {
+	return game.BotColor != chess.NoColor && game.ChessGame.Position().Turn() == game.BotColor
+}
+
+// playBotMove lets the engine move for its side, recording it as a turn.
+func (game *Game) playBotMove(ctx context.Context) {
+	var analyzer engine.Analyzer
+	limits := engine.Limits{Depth: game.BotStrength, MoveTime: game.searchTime()}
+	switch {
+	case game.Engine != nil:
+		// External engines are much stronger per ply, keep their depth modest.
+		analyzer = game.Engine
+		limits.Depth = game.BotStrength * 3
+	case game.Searcher != nil:
+		analyzer = game.Searcher
+	}
+
+	// Without an engine, fallbackMove picks a random move.
+	move := ""
+	if analyzer != nil {
+		search, cancel := game.searchContext(ctx)
+		var err error
+		move, err = engine.BestMove(search, analyzer, game.ChessGame.FEN(), limits)
+		cancel()
+		if err != nil {
+			fmt.Printf("Bot engine failed, playing a fallback move: %v\n", err)
+		}
+	}
+	if move == "" {
+		move = game.fallbackMove(ctx)
+	}
+	if move == "" {
+		return
+	}
+
+	m, err := chess.UCINotation{}.Decode(game.ChessGame.Position(), move)
+	if err != nil || game.ChessGame.Move(m) != nil {
+		fmt.Printf("Bot engine returned an illegal move %s\n", move)
+		return
+	}
+	game.RecentMove = move
+	game.recordTurn(nil, nil)
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+func TestSearchTime(t *testing.T) {
+	game := NewGame()
+	if got := game.searchTime(); got != DefaultSearchTime {
+		t.Errorf("search time %v, want the default %v", got, DefaultSearchTime)
+	}
+	game.Searcher = &engine.Searcher{MoveTime: 10 * time.Second}
+	if got := game.searchTime(); got != 10*time.Second {
+		t.Errorf("search time %v, want the searcher's 10s", got)
+	}
+	game.Searcher = nil
+	if got := game.searchTime(); got != DefaultSearchTime {
+		t.Errorf("search time without a searcher %v, want %v", got, DefaultSearchTime)
+	}
+}
+
+func TestNextBotTurn(t *testing.T) {
+	game := NewGame()
+	game.StartBotGame(chess.White, MaxBotStrength)
+	game.AddWhitePlayer("w1")
+	// Too deep to finish, so each search runs for its whole time.
+	const moveTime = 300 * time.Millisecond
+	game.Searcher = &engine.Searcher{Depth: 30, MoveTime: moveTime}
+
+	start := time.Now()
+	if msg := game.Next(context.Background()); msg != "" {
+		t.Fatalf("game ended: %s", msg)
+	}
+	elapsed := time.Since(start)
+
+	// Nobody voted: the engine moved for the community, then for itself.
+	if n := len(game.ChessGame.Moves()); n != 2 {
+		t.Fatalf("%d moves played, want 2", n)
+	}
+	if game.Turn {
+		t.Error("black to move after the bot's reply")
+	}
+	if elapsed < 2*moveTime || elapsed > 2*(moveTime+searchGrace) {
+		t.Errorf("two engine moves took %v, want each to search for %v", elapsed, moveTime)
+	}
+}
@@ -1,20 +1,26 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"image/color"
 	"strconv"
 	"strings"
 	"time"
 
 	"hunsuChess/chess"
+	"hunsuChess/endgame"
 	"hunsuChess/game"
+	"hunsuChess/opening"
+	"hunsuChess/tactics"
 
 	"github.com/bwmarrin/discordgo"
 	notnilchess "github.com/notnil/chess"
 )
 
 type InteractionHandler struct {
-	Game *game.Game
+	Game   *game.Game
+	Themes *chess.ThemeSettings
 }
 
 func (h *InteractionHandler) Handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -67,7 +73,16 @@ func (h *InteractionHandler) Handle(s *discordgo.Session, i *discordgo.Interacti
 			h.handleMoveVote(s, i, customID)
 		case customID == chess.PrefixMoveCancel:
 			h.handleMoveCancel(s, i)
+		case strings.HasPrefix(customID, chess.PrefixMoveReason):
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseModal,
+				Data: chess.CreateReasonModal(h.Game.ChessGame, strings.TrimPrefix(customID, chess.PrefixMoveReason), User.ID),
+			})
+		case strings.HasPrefix(customID, chess.PrefixReasonLike):
+			h.handleReasonLike(s, i, customID)
 		}
+	case discordgo.InteractionModalSubmit:
+		h.handleReasonSubmit(s, i)
 	}
 }
 
@@ -83,6 +98,36 @@ func (h *InteractionHandler) handleApplicationCommand(s *discordgo.Session, i *d
 		h.handleJoinCommand(s, i)
 	case "move":
 		h.handleMoveCommand(s, i)
+	case "mode":
+		h.handleModeCommand(s, i)
+	case "vsbot":
+		h.handleVsBotCommand(s, i)
+	case "propose":
+		h.handleProposeCommand(s, i)
+	case "reasons":
+		h.handleReasonsCommand(s, i)
+	case "runoff":
+		h.handleRunoffCommand(s, i)
+	case "delegate":
+		h.handleDelegateCommand(s, i)
+	case "captain":
+		h.handleCaptainCommand(s, i)
+	case "appoint":
+		h.handleAppointCommand(s, i)
+	case "veto":
+		h.handleVetoCommand(s, i)
+	case "history":
+		h.handleHistoryCommand(s, i)
+	case "hint":
+		h.handleHintCommand(s, i)
+	case "coach":
+		h.handleCoachCommand(s, i)
+	case "evalbar":
+		h.handleEvalBarCommand(s, i)
+	case "replay":
+		h.handleReplayCommand(s, i)
+	case "theme", "servertheme":
+		h.handleThemeCommand(s, i)
 	// case "skip":
 	// 	h.handleSkipCommand(s, i)
 	// case "vote":
@@ -103,7 +148,16 @@ func (h *InteractionHandler) handleHelpCommand(s *discordgo.Session, i *discordg
 		"하루가 지나갈 때마다 턴이 넘어가며, 각 팀의 플레이어들은 자신의 턴에 투표를 할 수 있습니다.\n\n" +
 		"**/join**: 게임에 참여합니다.\n" +
 		"**/game**: 현재 게임 상태를 확인합니다.\n" +
-		"**/move**: 두고 싶은 수에 투표합니다.\n\n" +
+		"**/move**: 두고 싶은 수에 투표합니다.\n" +
+		"**/reasons**: 팀원들이 투표에 남긴 이유를 보고 공감을 누릅니다.\n" +
+		"**/propose**: 결선 투표가 켜져 있으면 제안 단계에서 이유와 함께 수를 추천합니다.\n" +
+		"**/delegate**: 투표하지 않은 턴에는 지정한 팀원의 투표를 따릅니다.\n" +
+		"**/captain**: 팀 주장 선거에 투표합니다. 주장은 한 턴에 한 번 **/veto**로 수를 거부할 수 있고, 동점일 때 주장의 표가 우선합니다.\n" +
+		"**/history**: 최근 턴의 기록을 확인합니다.\n" +
+		"**/replay**: 게임을 움직이는 GIF로 다시 봅니다. 게임이 끝나면 자동으로 올라옵니다.\n" +
+		"**/theme**: 보드 테마, 기물 세트와 이미지 크기를 고릅니다.\n" +
+		"**/hint**: 공격받는 기물, 핀, 메이트 위협을 보드에 표시합니다.\n" +
+		fmt.Sprintf("**/coach**: 팀원 과반이 찬성하면 코치 상담을 사용해 엔진의 추천 수를 봅니다. 한 게임에 팀마다 %d번 사용할 수 있습니다.\n\n", h.Game.CoachBudget) +
 		"봇에 관련된 피드백 또는 버그 제보는 **@number_er**으로 연락해주시면 감사하겠습니다."
 
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -147,6 +201,27 @@ func (h *InteractionHandler) handleGameCommand(s *discordgo.Session, i *discordg
 		}
 
 		message = fmt.Sprintf("%s팀 차례가 넘어갈 때까지 %d시간 %d분 %d초 남았습니다.\n\n%s", turn, hours, minutes, seconds, h.Game.GetTopNVotes(3))
+		if h.Game.Mode == game.DeciderMode && h.Game.Decider != "" {
+			message = fmt.Sprintf("이번 턴의 결정권자: <@%s>\n%s", h.Game.Decider, message)
+		}
+		if h.Game.Vetoed != "" {
+			message += fmt.Sprintf("\n주장이 거부한 수: %s", h.Game.Vetoed)
+		}
+		if summary := endgame.Summary(h.Game.ChessGame.Position()); summary != "" {
+			message += "\n엔드게임 테이블: " + summary
+		}
+		if o := opening.Find(h.Game.ChessGame); o != nil {
+			message = fmt.Sprintf("오프닝: %s\n%s", o, message)
+		}
+		switch h.Game.CommunityTeam() {
+		case "":
+			message += fmt.Sprintf("\n코치 상담 남은 횟수: 백팀 %d/%d · 흑팀 %d/%d", h.Game.CoachLeft("white"), h.Game.CoachBudget, h.Game.CoachLeft("black"), h.Game.CoachBudget)
+		default:
+			message += fmt.Sprintf("\n코치 상담 남은 횟수: %d/%d", h.Game.CoachLeft(h.Game.CommunityTeam()), h.Game.CoachBudget)
+		}
+		if h.Game.CommunityTeam() != "" {
+			message = fmt.Sprintf("엔진(강도 %d)과 대결 중입니다.\n%s", h.Game.BotStrength, message)
+		}
 	}
 
 	var User *discordgo.User
@@ -169,29 +244,46 @@ func (h *InteractionHandler) handleGameCommand(s *discordgo.Session, i *discordg
 	}
 
 	team, _ := h.Game.GetPlayerTeam(User.ID)
-	fen := h.Game.ChessGame.FEN()
-	if team == "black" {
-		parts := strings.Split(fen, " ")
-		runes := []rune(parts[0])
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		parts[0] = string(runes)
-		fen = strings.Join(parts, " ")
+	// Those checking in once a day see both moves played since their last turn.
+	last, previous := chess.LastMoves(h.Game.ChessGame)
+	opts := chess.RenderOptions{
+		Orientation:  chess.Orientation(team),
+		Votes:        h.Game.GetVotes(),
+		LastMove:     last,
+		PreviousMove: previous,
+		Material:     true,
+		Theme:        h.Themes.Resolve(i.GuildID, User.ID),
+	}
+	if !h.Game.IsGameOver() {
+		opts.Caption = turnBanner(h.Game)
+	}
+	pos := h.Game.ChessGame.Position()
+
+	// The evaluation may take longer than Discord waits for a reply.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		fmt.Printf("Error deferring interaction response: %v\n", err)
+		return
 	}
 
-	file := chess.ChessImage(fen, h.Game.GetVotes(), team)
+	if h.Game.EvalBar && !h.Game.IsGameOver() {
+		// The bar is left out if the analysis is slow.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if score, err := h.Game.Evaluation(ctx); err == nil {
+			opts.Eval = &score
+		}
+		cancel()
+	}
+	file := chess.Render(pos, opts)
 
-	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: message,
-			Files: []*discordgo.File{
-				{
-					Name:        "chess.png",
-					ContentType: "image/png",
-					Reader:      file,
-				},
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &message,
+		Files: []*discordgo.File{
+			{
+				Name:        "chess.png",
+				ContentType: "image/png",
+				Reader:      file,
 			},
 		},
 	})
@@ -215,8 +307,17 @@ func (h *InteractionHandler) handleJoinCommand(s *discordgo.Session, i *discordg
 		isPremium = isPremium || (i.Member != nil && i.Member.PremiumSince != nil) || (member != nil && member.Avatar != "")
 	}
 
+	// Against the bot everyone plays on the community's side.
+	switch h.Game.CommunityTeam() {
+	case "white":
+		isPremium = true
+	case "black":
+		isPremium = false
+	}
+
 	if isPremium {
 		h.Game.AddWhitePlayer(User.ID)
+		h.Game.SetTeamChannel("white", i.ChannelID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -225,6 +326,7 @@ func (h *InteractionHandler) handleJoinCommand(s *discordgo.Session, i *discordg
 		})
 	} else {
 		h.Game.AddBlackPlayer(User.ID)
+		h.Game.SetTeamChannel("black", i.ChannelID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -238,10 +340,13 @@ func (h *InteractionHandler) handleJoinCommand(s *discordgo.Session, i *discordg
 func (h *InteractionHandler) handleMoveCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	options := i.ApplicationCommandData().Options
 	var moveUCI string
+	var withReason bool
 	for _, opt := range options {
-		if opt.Name == "move_uci" {
+		switch opt.Name {
+		case "move_uci":
 			moveUCI = opt.StringValue()
-			break
+		case "with_reason":
+			withReason = opt.BoolValue()
 		}
 	}
 
@@ -264,9 +369,35 @@ func (h *InteractionHandler) handleMoveCommand(s *discordgo.Session, i *discordg
 		return
 	}
 
-	if moveUCI != "" {
+	if moveUCI != "" && withReason {
+		// Ask for the reason first, the vote is cast when the modal is submitted
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: chess.CreateReasonModal(h.Game.ChessGame, moveUCI, User.ID),
+		})
+	} else if moveUCI != "" {
 		// If move_uci is provided, attempt to vote for it
 		err := h.Game.VoteMove(User.ID, moveUCI)
+		if err == game.ErrNotCandidate {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("결선 투표 중에는 후보에만 투표할 수 있습니다: **%s**", strings.Join(h.Game.CandidateSANs(), "**, **")),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		if err == game.ErrVetoed {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("`%s`는 이번 턴에 주장이 거부한 수입니다.", moveUCI),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
 		if err != nil {
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -280,14 +411,20 @@ func (h *InteractionHandler) handleMoveCommand(s *discordgo.Session, i *discordg
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("%s님이 **%s**에 투표했습니다.", User.Username, moveUCI),
+				Content: fmt.Sprintf("%s님이 **%s**에 투표했습니다.%s", User.Username, moveUCI, h.voteNotice(User.ID)),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 	} else {
-		// If no move_uci, display the initial move embed
+		// If no move_uci, display the initial move embed, or only the candidates during the runoff
 		team, _ := h.Game.GetPlayerTeam(User.ID)
-		messageToSend, err := chess.CreateInitialMoveEmbed(h.Game.ChessGame, User.ID, team, true)
+		var messageToSend *discordgo.MessageSend
+		var err error
+		if h.Game.InRunoff() {
+			messageToSend, err = chess.CreateRunoffEmbed(h.Game.ChessGame, h.Game.Candidates, User.ID, team, h.Themes.Resolve(i.GuildID, User.ID))
+		} else {
+			messageToSend, err = chess.CreateInitialMoveEmbed(h.Game.ChessGame, User.ID, team, true, h.Themes.Resolve(i.GuildID, User.ID))
+		}
 		if err != nil {
 			fmt.Printf("Error creating initial move embed: %v\n", err)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -300,6 +437,9 @@ func (h *InteractionHandler) handleMoveCommand(s *discordgo.Session, i *discordg
 			return
 		}
 		messageToSend.Flags = discordgo.MessageFlagsEphemeral // Ensure it's ephemeral
+		if h.Game.IsDecider(User.ID) {
+			messageToSend.Content = "당신은 이번 턴의 결정권자입니다. 팀원들의 제안을 참고하세요.\n\n" + h.Game.GetTopNVotes(5)
+		}
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -334,21 +474,16 @@ func (h *InteractionHandler) handleSkipCommand(s *discordgo.Session, i *discordg
 		return
 	}
 
-	resultMsg := h.Game.Next()
+	resultMsg := h.Game.Next(context.Background())
 
 	team, _ := h.Game.GetPlayerTeam(i.Member.User.ID)
-	fen := h.Game.ChessGame.FEN()
-	if team == "black" {
-		parts := strings.Split(fen, " ")
-		runes := []rune(parts[0])
-		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
-			runes[i], runes[j] = runes[j], runes[i]
-		}
-		parts[0] = string(runes)
-		fen = strings.Join(parts, " ")
-	}
-
-	file := chess.ChessImage(fen, h.Game.GetVotes(), team)
+	last, _ := chess.LastMoves(h.Game.ChessGame)
+	file := chess.Render(h.Game.ChessGame.Position(), chess.RenderOptions{
+		Orientation: chess.Orientation(team),
+		Votes:       h.Game.GetVotes(),
+		LastMove:    last,
+		Theme:       h.Themes.Resolve(i.GuildID, i.Member.User.ID),
+	})
 	var message string
 	if resultMsg != "" {
 		message = resultMsg
@@ -393,7 +528,7 @@ func (h *InteractionHandler) handleMovePage(s *discordgo.Session, i *discordgo.I
 	page, _ := strconv.Atoi(pageStr)
 	team, _ := h.Game.GetPlayerTeam(User.ID)
 
-	messageToEdit, err := chess.CreatePaginationMessageEdit(h.Game.ChessGame, page, h.Game.GetVotes(), User.ID, team)
+	messageToEdit, err := chess.CreatePaginationMessageEdit(h.Game.ChessGame, page, h.Game.GetVotes(), User.ID, team, h.Themes.Resolve(i.GuildID, User.ID))
 	if err != nil {
 		fmt.Printf("Error creating pagination embed: %v\n", err)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -423,7 +558,7 @@ func (h *InteractionHandler) handleMoveSelect(s *discordgo.Session, i *discordgo
 	moveStr := strings.TrimPrefix(customID, chess.PrefixMoveSelect)
 	team, _ := h.Game.GetPlayerTeam(User.ID)
 
-	messageToEdit, err := chess.CreateMovePreviewEmbed(h.Game.ChessGame, moveStr, User.ID, team)
+	messageToEdit, err := chess.CreateMovePreviewEmbed(h.Game.ChessGame, moveStr, User.ID, team, h.Themes.Resolve(i.GuildID, User.ID))
 	if err != nil {
 		fmt.Printf("Error creating move preview embed: %v\n", err)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -444,12 +579,20 @@ func (h *InteractionHandler) handleMoveVote(s *discordgo.Session, i *discordgo.I
 	}
 
 	moveStr := strings.TrimPrefix(customID, chess.PrefixMoveVote)
-	err := h.Game.VoteMove(User.ID, moveStr)
-	if err != nil {
+	if err := h.Game.VoteMove(User.ID, moveStr); err != nil {
+		var message string
+		switch err {
+		case game.ErrVetoed:
+			message = fmt.Sprintf("`%s`는 이번 턴에 주장이 거부한 수입니다.", moveStr)
+		case game.ErrNotCandidate:
+			message = fmt.Sprintf("결선 투표 중에는 후보에만 투표할 수 있습니다: **%s**", strings.Join(h.Game.CandidateSANs(), "**, **"))
+		default:
+			message = "투표 중 오류가 발생했습니다."
+		}
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "투표 중 오류가 발생했습니다.",
+				Content: message,
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -467,7 +610,7 @@ func (h *InteractionHandler) handleMoveVote(s *discordgo.Session, i *discordgo.I
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("**%s**에 대한 투표가 완료되었습니다!", san),
+			Content: fmt.Sprintf("**%s**에 대한 투표가 완료되었습니다!%s", san, h.voteNotice(User.ID)),
 		},
 	})
 }
@@ -490,7 +633,7 @@ func (h *InteractionHandler) handleMoveCancel(s *discordgo.Session, i *discordgo
 
 	team, _ := h.Game.GetPlayerTeam(User.ID)
 
-	messageToEdit, err := chess.CreatePaginationMessageEdit(h.Game.ChessGame, 0, h.Game.GetVotes(), User.ID, team)
+	messageToEdit, err := chess.CreatePaginationMessageEdit(h.Game.ChessGame, 0, h.Game.GetVotes(), User.ID, team, h.Themes.Resolve(i.GuildID, User.ID))
 	if err != nil {
 		fmt.Printf("Error creating pagination embed: %v\n", err)
 		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
@@ -500,3 +643,853 @@ func (h *InteractionHandler) handleMoveCancel(s *discordgo.Session, i *discordgo
 	}
 	s.InteractionResponseEdit(i.Interaction, MessageEditToWebhookEdit(messageToEdit))
 }
+
+// voteNotice explains how the player's vote is used in the current decision mode.
+func (h *InteractionHandler) voteNotice(userID string) string {
+	if h.Game.Mode != game.DeciderMode {
+		return ""
+	}
+	if h.Game.IsDecider(userID) {
+		return "\n결정권자의 선택이 이번 턴의 수로 결정됩니다."
+	}
+	return fmt.Sprintf("\n투표는 결정권자 <@%s>님에게 제안으로 전달됩니다.", h.Game.Decider)
+}
+
+func (h *InteractionHandler) handleModeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var mode game.DecisionMode
+	var message string
+
+	switch i.ApplicationCommandData().Options[0].StringValue() {
+	case "decider":
+		mode = game.DeciderMode
+		message = "결정권자 모드로 변경되었습니다. 매 턴 무작위로 선택된 팀원이 수를 결정합니다."
+	default:
+		mode = game.MajorityMode
+		message = "다수결 모드로 변경되었습니다."
+	}
+
+	h.Game.SetMode(mode)
+	if h.Game.Decider != "" {
+		message += fmt.Sprintf("\n이번 턴의 결정권자는 <@%s>님입니다.", h.Game.Decider)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleDelegateCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayer(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var target *discordgo.User
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "user" {
+			target = opt.UserValue(s)
+		}
+	}
+
+	var message string
+	if target == nil {
+		h.Game.Delegate(User.ID, "")
+		message = "투표 위임이 취소되었습니다."
+	} else if err := h.Game.Delegate(User.ID, target.ID); err != nil {
+		switch err {
+		case game.ErrDelegationCycle:
+			message = "위임이 순환되므로 위임할 수 없습니다."
+		case game.ErrSelfDelegation:
+			message = "자기 자신에게는 위임할 수 없습니다."
+		default:
+			message = "같은 팀원에게만 위임할 수 있습니다."
+		}
+	} else {
+		message = fmt.Sprintf("<@%s>님에게 투표를 위임했습니다. 직접 투표한 턴에는 본인의 투표가 반영됩니다.", target.ID)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleCaptainCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayer(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	candidate := i.ApplicationCommandData().Options[0].UserValue(s)
+	elected, err := h.Game.VoteCaptain(User.ID, candidate.ID)
+
+	var message string
+	var flags discordgo.MessageFlags = discordgo.MessageFlagsEphemeral
+	switch {
+	case err != nil:
+		message = "같은 팀원만 주장으로 지지할 수 있습니다."
+	case elected:
+		message = fmt.Sprintf("<@%s>님이 팀 주장으로 선출되었습니다!", candidate.ID)
+		flags = 0
+	default:
+		message = fmt.Sprintf("<@%s>님을 주장으로 지지했습니다.", candidate.ID)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   flags,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleAppointCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	target := i.ApplicationCommandData().Options[0].UserValue(s)
+
+	var message string
+	if err := h.Game.AppointCaptain(target.ID); err != nil {
+		message = "게임에 참여한 플레이어만 주장으로 임명할 수 있습니다."
+	} else {
+		message = fmt.Sprintf("<@%s>님이 팀 주장으로 임명되었습니다.", target.ID)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleVetoCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayerAndTurn(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	moveStr := i.ApplicationCommandData().Options[0].StringValue()
+	san, err := h.Game.Veto(User.ID, moveStr)
+
+	var message string
+	var flags discordgo.MessageFlags = discordgo.MessageFlagsEphemeral
+	switch err {
+	case nil:
+		message = fmt.Sprintf("주장 <@%s>님이 **%s**에 거부권을 행사했습니다. 이 수에 투표한 표는 무효가 되었습니다.", User.ID, san)
+		flags = 0
+	case game.ErrNotCaptain:
+		message = "주장만 거부권을 사용할 수 있습니다."
+	case game.ErrAlreadyVetoed:
+		message = "이번 턴에 이미 거부권을 사용했습니다."
+	default:
+		message = fmt.Sprintf("잘못된 수: `%s`.", moveStr)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   flags,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleHistoryCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: h.Game.GetHistory(10),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+		},
+	})
+}
+
+var hintColors = map[tactics.Kind]color.NRGBA{
+	tactics.Hanging:    {220, 40, 40, 150},
+	tactics.Attacked:   {240, 150, 30, 150},
+	tactics.Pinned:     {50, 110, 220, 150},
+	tactics.MateThreat: {160, 50, 200, 150},
+}
+
+func (h *InteractionHandler) handleHintCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayer(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	overlay := true
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "overlay" {
+			overlay = opt.BoolValue()
+		}
+	}
+
+	team, _ := h.Game.GetPlayerTeam(User.ID)
+	ours := notnilchess.White
+	if team == "black" {
+		ours = notnilchess.Black
+	}
+
+	var threats, chances []string
+	var highlights []chess.Highlight
+	var arrows []string
+	for _, hint := range tactics.Analyze(h.Game.ChessGame.Position()) {
+		if hint.Color == ours {
+			threats = append(threats, "- "+hint.String())
+		} else {
+			chances = append(chances, "- "+hint.String())
+		}
+		highlights = append(highlights, chess.Highlight{Square: hint.Square.String(), Color: hintColors[hint.Kind]})
+		if hint.Kind == tactics.MateThreat {
+			arrows = append(arrows, hint.Move)
+		}
+	}
+
+	var message string
+	if len(threats) == 0 && len(chances) == 0 {
+		message = "눈에 띄는 전술적 약점이 없습니다."
+	} else {
+		if len(threats) > 0 {
+			message += "**우리 팀이 조심할 점**\n" + strings.Join(threats, "\n") + "\n"
+		}
+		if len(chances) > 0 {
+			message += "**노려볼 만한 점**\n" + strings.Join(chances, "\n") + "\n"
+		}
+		message += "\n엔진 없이 정적으로 계산한 힌트라 깊은 수읽기는 반영되지 않습니다."
+	}
+
+	data := &discordgo.InteractionResponseData{
+		Content: message,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}
+	if overlay {
+		data.Files = []*discordgo.File{
+			{
+				Name:        "hint.png",
+				ContentType: "image/png",
+				Reader: chess.Render(h.Game.ChessGame.Position(), chess.RenderOptions{
+					Orientation: chess.Orientation(team),
+					Arrows:      arrows,
+					Highlights:  highlights,
+					Theme:       h.Themes.Resolve(i.GuildID, User.ID),
+				}),
+			},
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+func (h *InteractionHandler) handleCoachCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if errMsg := CheckPlayerAndTurn(h.Game, User.ID); errMsg != "" {
+		respond(errMsg)
+		return
+	}
+
+	spent, err := h.Game.VoteCoach(User.ID)
+	switch {
+	case err == game.ErrNoCoachBudget:
+		respond("이번 게임의 코치 상담을 모두 사용했습니다.")
+		return
+	case err == game.ErrNotTurn:
+		respond("상대 팀의 차례에는 코치 상담에 투표할 수 없습니다.")
+		return
+	case err != nil:
+		respond("코치 상담에 투표할 수 없습니다.")
+		return
+	case !h.Game.Coached:
+		votes, size := h.Game.CoachVotes()
+		respond(fmt.Sprintf("코치 상담에 찬성했습니다. (%d/%d명) 팀원 과반이 찬성하면 상담을 사용합니다.", votes, size))
+		return
+	}
+
+	// The analysis takes a few seconds, the result is shown to this player only.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}); err != nil {
+		fmt.Printf("Error deferring interaction response: %v\n", err)
+		return
+	}
+
+	team, _ := h.Game.GetPlayerTeam(User.ID)
+	if spent {
+		if channelID := h.Game.TurnChannelID(); channelID != "" {
+			announcement := fmt.Sprintf("팀 투표로 코치 상담을 사용했습니다. (남은 횟수 %d) `/coach`로 추천 수를 확인하세요.", h.Game.CoachLeft(team))
+			if _, err := s.ChannelMessageSend(channelID, announcement); err != nil {
+				fmt.Printf("Cannot announce coach consultation in %s: %v\n", channelID, err)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	pos := h.Game.ChessGame.Position()
+	lines, err := h.Game.Consult(ctx)
+	if err != nil || len(lines) == 0 {
+		fmt.Printf("Coach analysis failed: %v\n", err)
+		message := "코치가 국면을 분석하지 못했습니다. 잠시 후 `/coach`로 다시 확인하세요."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &message})
+		return
+	}
+
+	var rows []string
+	var arrows []string
+	for n, line := range lines {
+		rows = append(rows, fmt.Sprintf("%d. **%s** (%s) %s", n+1, h.Game.SAN(line.Move), line.Score, lineSAN(pos, line.PV, 5)))
+		arrows = append(arrows, line.Move)
+	}
+	message := "코치의 추천 수 (우리 팀 기준 평가):\n" + strings.Join(rows, "\n")
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &message,
+		Files: []*discordgo.File{
+			{
+				Name:        "coach.png",
+				ContentType: "image/png",
+				Reader: chess.Render(pos, chess.RenderOptions{
+					Orientation: chess.Orientation(team),
+					Arrows:      arrows,
+					Theme:       h.Themes.Resolve(i.GuildID, User.ID),
+				}),
+			},
+		},
+	})
+}
+
+// lineSAN writes the first n moves of a line given in UCI in algebraic notation.
+func lineSAN(pos *notnilchess.Position, pv []string, n int) string {
+	var moves []string
+	for _, uci := range pv {
+		if len(moves) == n {
+			break
+		}
+		m, err := notnilchess.UCINotation{}.Decode(pos, uci)
+		if err != nil {
+			break
+		}
+		moves = append(moves, notnilchess.AlgebraicNotation{}.Encode(pos, m))
+		pos = pos.Update(m)
+	}
+	return strings.Join(moves, " ")
+}
+
+func (h *InteractionHandler) handleReplayCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	opts := chess.ReplayOptions{Theme: h.Themes.Resolve(i.GuildID, User.ID)}
+	if team, ok := h.Game.GetPlayerTeam(User.ID); ok {
+		opts.Orientation = chess.Orientation(team)
+	}
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "from":
+			opts.From = int(opt.IntValue())
+		case "to":
+			to := int(opt.IntValue())
+			opts.To = &to
+		case "delay":
+			opts.Delay = time.Duration(opt.FloatValue() * float64(time.Second))
+		}
+	}
+
+	// Drawing every position takes a while for long games.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		fmt.Printf("Error deferring interaction response: %v\n", err)
+		return
+	}
+
+	replay, err := chess.Replay(h.Game.ChessGame.Clone(), opts)
+	if err != nil {
+		message := "다시 볼 수가 없습니다. 수가 두어졌는지, 반수 범위가 올바른지 확인하세요."
+		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &message})
+		return
+	}
+
+	message := fmt.Sprintf("%s님이 요청한 게임 다시 보기", User.Username)
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &message,
+		Files: []*discordgo.File{
+			{
+				Name:        "replay.gif",
+				ContentType: "image/gif",
+				Reader:      replay,
+			},
+		},
+	})
+}
+
+// ThemeReset is the /theme choice that goes back to the server default.
+const ThemeReset = "reset"
+
+func (h *InteractionHandler) handleThemeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	var choice chess.ThemeChoice
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "theme":
+			choice.Theme = opt.StringValue()
+		case "pieces":
+			choice.Pieces = opt.StringValue()
+		case "size":
+			choice.Size = chess.BoardSize(int(opt.IntValue()))
+		}
+	}
+
+	var message string
+	var err error
+	switch {
+	case i.ApplicationCommandData().Name == "servertheme":
+		err = h.Themes.SetGuildDefault(i.GuildID, choice)
+		message = "서버의 기본 보드 설정을 바꿨습니다."
+	case choice.Theme == ThemeReset:
+		err = h.Themes.SetUser(User.ID, chess.ThemeChoice{})
+		message = "서버의 기본 보드 설정을 사용합니다."
+	default:
+		err = h.Themes.SetUser(User.ID, choice)
+		message = "보드 설정을 바꿨습니다."
+	}
+	if choice.Theme != "" && choice.Theme != ThemeReset {
+		message += fmt.Sprintf(" 테마: **%s**", choice.Theme)
+	}
+	if choice.Pieces != "" {
+		message += fmt.Sprintf(" 기물 세트: **%s**", choice.Pieces)
+	}
+	if choice.Size != 0 {
+		message += fmt.Sprintf(" 크기: **%dpx**", choice.Size)
+	}
+	if err != nil {
+		fmt.Printf("Cannot save theme settings: %v\n", err)
+		message += "\n설정을 저장하지 못해 봇이 다시 시작되면 사라집니다."
+	}
+
+	// Show the result on the starting position, from white's side.
+	preview := chess.Render(notnilchess.StartingPosition(), chess.RenderOptions{Theme: h.Themes.Resolve(i.GuildID, User.ID)})
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Files: []*discordgo.File{
+				{
+					Name:        "theme.png",
+					ContentType: "image/png",
+					Reader:      preview,
+				},
+			},
+		},
+	})
+}
+
+func (h *InteractionHandler) handleProposeCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayerAndTurn(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var moveStr, reason string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "move":
+			moveStr = opt.StringValue()
+		case "reason":
+			reason = opt.StringValue()
+		}
+	}
+
+	var message string
+	switch err := h.Game.Propose(User.ID, moveStr, reason); err {
+	case nil:
+		message = fmt.Sprintf("**%s**를 후보로 추천했습니다: \"%s\"", moveStr, reason)
+	case game.ErrRunoffStarted:
+		message = "제안 단계가 끝났습니다. `/move`로 결선 후보에 투표하세요."
+	case game.ErrVetoed:
+		message = fmt.Sprintf("`%s`는 이번 턴에 주장이 거부한 수입니다.", moveStr)
+	default:
+		message = fmt.Sprintf("잘못된 수: `%s`. `/move`를 사용하여 가능한 수를 확인하세요.", moveStr)
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleRunoffCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var enabled bool
+	size := game.DefaultRunoffSize
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "enabled":
+			enabled = opt.BoolValue()
+		case "candidates":
+			size = int(opt.IntValue())
+		}
+	}
+
+	h.Game.SetRunoff(enabled, size)
+
+	var message string
+	if enabled {
+		message = fmt.Sprintf("결선 투표가 켜졌습니다. 마감 전 마지막 구간에는 상위 %d개의 추천 수에만 투표할 수 있습니다.", size)
+	} else {
+		message = "결선 투표가 꺼졌습니다."
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleEvalBarCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	enabled := i.ApplicationCommandData().Options[0].BoolValue()
+	h.Game.SetEvalBar(enabled)
+
+	message := "평가 막대가 꺼졌습니다."
+	if enabled {
+		message = "평가 막대가 켜졌습니다. `/game`의 보드 옆에 엔진이 본 형세가 표시됩니다."
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleReasonSubmit(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	data := i.ModalSubmitData()
+	parts := strings.Split(data.CustomID, ";")
+	if len(parts) != 2 || parts[1] != User.ID || !strings.HasPrefix(parts[0], chess.PrefixMoveReason) {
+		return
+	}
+	moveStr := strings.TrimPrefix(parts[0], chess.PrefixMoveReason)
+
+	var reason string
+	for _, row := range data.Components {
+		actionsRow, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range actionsRow.Components {
+			if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == chess.ReasonInputID {
+				reason = input.Value
+			}
+		}
+	}
+
+	var message string
+	if errMsg := CheckPlayerAndTurn(h.Game, User.ID); errMsg != "" {
+		message = errMsg
+	} else if err := h.Game.VoteMoveWithReason(User.ID, moveStr, reason); err != nil {
+		message = fmt.Sprintf("`%s`에 투표할 수 없습니다. `/move`를 사용하여 가능한 수를 확인하세요.", moveStr)
+	} else if strings.TrimSpace(reason) != "" {
+		message = fmt.Sprintf("**%s**에 투표했습니다: \"%s\"%s", moveStr, strings.TrimSpace(reason), h.voteNotice(User.ID))
+	} else {
+		message = fmt.Sprintf("**%s**에 투표했습니다.%s", moveStr, h.voteNotice(User.ID))
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (h *InteractionHandler) handleReasonsCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	if errMsg := CheckPlayerAndTurn(h.Game, User.ID); errMsg != "" {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: errMsg,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: h.reasonListData(User.ID),
+	})
+}
+
+func (h *InteractionHandler) handleReasonLike(s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	var User *discordgo.User
+
+	if i.Member == nil {
+		User = i.User
+	} else {
+		User = i.Member.User
+	}
+
+	author := strings.TrimPrefix(customID, chess.PrefixReasonLike)
+	if err := h.Game.LikeReason(User.ID, author); err != nil {
+		var message string
+		if err == game.ErrOwnReason {
+			message = "자신의 이유에는 공감할 수 없습니다."
+		} else {
+			message = "이 이유는 더 이상 유효하지 않습니다."
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: message,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: h.reasonListData(User.ID),
+	})
+}
+
+// reasonListData lists the reasons of the team to move with a like button for each.
+func (h *InteractionHandler) reasonListData(userID string) *discordgo.InteractionResponseData {
+	reasons := h.Game.Reasons()
+	if len(reasons) == 0 {
+		return &discordgo.InteractionResponseData{
+			Content: "아직 이유가 달린 투표가 없습니다. `/move`의 \"Vote with Reason\" 버튼으로 이유를 남겨보세요.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		}
+	}
+
+	// Discord allows 5 rows of 5 buttons.
+	if len(reasons) > 25 {
+		reasons = reasons[:25]
+	}
+
+	var lines []string
+	var components []discordgo.MessageComponent
+	var row discordgo.ActionsRow
+	for n, reason := range reasons {
+		lines = append(lines, fmt.Sprintf("%d. **%s** \"%s\" (👍 %d)", n+1, h.Game.SAN(reason.Move), reason.Text, reason.Likes))
+
+		if len(row.Components) == 5 {
+			components = append(components, row)
+			row = discordgo.ActionsRow{}
+		}
+		row.Components = append(row.Components, discordgo.Button{
+			Label:    fmt.Sprintf("👍 %d", n+1),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("%s%s;%s", chess.PrefixReasonLike, reason.Author, userID),
+			Disabled: reason.Author == userID,
+		})
+	}
+	components = append(components, row)
+
+	return &discordgo.InteractionResponseData{
+		Content:    "투표 이유 목록:\n" + strings.Join(lines, "\n"),
+		Components: components,
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}
+}
+
+func (h *InteractionHandler) handleVsBotCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	color := notnilchess.NoColor
+	strength := 3
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "color":
+			switch opt.StringValue() {
+			case "white":
+				color = notnilchess.White
+			case "black":
+				color = notnilchess.Black
+			}
+		case "strength":
+			strength = int(opt.IntValue())
+		}
+	}
+
+	// Starting the game may let the engine play the first move, which takes a moment.
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		fmt.Printf("Error deferring interaction response: %v\n", err)
+		return
+	}
+
+	h.Game.StartBotGame(color, strength)
+
+	var message string
+	if color == notnilchess.NoColor {
+		message = "엔진 대결을 종료하고 팀 대항전으로 새 게임을 시작했습니다."
+	} else {
+		team := "백"
+		if color == notnilchess.Black {
+			team = "흑"
+		}
+		message = fmt.Sprintf("커뮤니티가 %s을 맡아 엔진(강도 %d)과 대결하는 새 게임을 시작했습니다. 모든 참가자는 %s팀으로 참여합니다.", team, h.Game.BotStrength, team)
+		if h.Game.RecentMove != "" {
+			message += fmt.Sprintf("\n엔진의 첫 수: **%s**", h.Game.RecentMove)
+		}
+	}
+
+	s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &message,
+	})
+}
+
+// turnBanner writes the move number, the side to move and the time left in
+// the turn, e.g. "12수 · 백 차례 · 3시간 남음".
+func turnBanner(g *game.Game) string {
+	turn := "백"
+	if g.ChessGame.Position().Turn() == notnilchess.Black {
+		turn = "흑"
+	}
+	number := len(g.ChessGame.Moves())/2 + 1
+
+	left := time.Until(g.NextTime)
+	var remaining string
+	switch {
+	case left >= time.Hour:
+		remaining = fmt.Sprintf("%d시간", int(left.Hours()))
+	case left >= time.Minute:
+		remaining = fmt.Sprintf("%d분", int(left.Minutes()))
+	default:
+		remaining = "1분 미만"
+	}
+	return fmt.Sprintf("%d수 · %s 차례 · %s 남음", number, turn, remaining)
+}
@@ -1,41 +1,120 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"time"
 
 	"hunsuChess/bot"
+	"hunsuChess/chess"
+	"hunsuChess/commentary"
+	"hunsuChess/engine"
 
 	"hunsuChess/game"
 )
 
 var (
-	token string
+	token      string
+	enginePath string
+	engineSize int
+
+	searchDepth int
+	searchTime  time.Duration
+
+	commentaryLang string
+	assetsDir      string
+	fontPath       string
+	themesPath     string
+)
+
+const (
+	// How long before the turn deadline the runoff between the top nominations starts.
+	runoffBeforeDeadline = 6 * time.Hour
+	// How long before the turn deadline the captains get their summary
+	// and the leading moves are checked for blunders.
+	summaryBeforeDeadline = time.Hour
 )
 
 func init() {
 	flag.StringVar(&token, "t", "", "Bot Token")
+	flag.StringVar(&enginePath, "engine", "", "Path of a UCI engine used for analysis")
+	flag.IntVar(&engineSize, "engine-pool", 2, "Number of engine processes")
+	flag.IntVar(&searchDepth, "search-depth", 4, "Depth of the built-in engine")
+	flag.DurationVar(&searchTime, "search-time", 10*time.Second, "Time an engine searches for each move it plays for a team")
+	flag.StringVar(&commentaryLang, "commentary", "ko", "Language of the move commentary: ko, en or off")
+	flag.StringVar(&assetsDir, "assets", "", "Directory of board and piece images replacing the built-in ones")
+	flag.StringVar(&fontPath, "font", "", "TrueType or OpenType font of the board labels and captions")
+	flag.StringVar(&themesPath, "themes", "themes.json", "File where the board theme preferences are saved")
 	flag.Parse()
 }
 
 func main() {
+	if assetsDir != "" {
+		if err := chess.LoadAssets(assetsDir); err != nil {
+			fmt.Printf("Some images in %s are not used, the built-in ones are kept:\n%v\n", assetsDir, err)
+		}
+	}
+	if fontPath != "" {
+		if err := chess.LoadFont(fontPath); err != nil {
+			fmt.Printf("The built-in fonts are kept: %v\n", err)
+		}
+	}
+
 	gameInstance := game.NewGame()
+	gameInstance.Searcher = &engine.Searcher{Depth: searchDepth, MoveTime: searchTime}
+
+	switch commentaryLang {
+	case "en":
+		gameInstance.Commentary = commentary.English
+	case "off":
+		gameInstance.Commentary = nil
+	}
 
-	go DayCycle(gameInstance)
+	if enginePath != "" {
+		pool, err := engine.NewPool(engine.Config{Path: enginePath, Size: engineSize})
+		if err != nil {
+			fmt.Printf("Cannot start engine, analysis is disabled: %v\n", err)
+		} else {
+			defer pool.Close()
+			gameInstance.Engine = pool
+		}
+	}
+
+	themes, err := chess.LoadThemeSettings(themesPath)
+	if err != nil {
+		fmt.Printf("Theme preferences are not saved: %v\n", err)
+		themes = chess.NewThemeSettings()
+	}
+
+	botInstance := bot.NewBot(gameInstance, themes)
+
+	go DayCycle(gameInstance, botInstance)
 
-	botInstance := bot.NewBot(gameInstance)
 	botInstance.Start(token)
 }
 
-func DayCycle(gameInstance *game.Game) {
+func DayCycle(gameInstance *game.Game, botInstance *bot.Bot) {
 	for {
 		now := time.Now().UTC().Add(24 * time.Hour)
 		year, month, day := now.Date()
 		gameInstance.NextTime = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+		if runoffTime := gameInstance.NextTime.Add(-runoffBeforeDeadline); time.Until(runoffTime) > 0 {
+			<-time.After(time.Until(runoffTime))
+			botInstance.StartRunoff()
+		}
+
+		if summaryTime := gameInstance.NextTime.Add(-summaryBeforeDeadline); time.Until(summaryTime) > 0 {
+			<-time.After(time.Until(summaryTime))
+			botInstance.BeforeDeadline()
+			botInstance.BlunderAlert()
+		}
+
 		<-time.After(time.Until(gameInstance.NextTime))
 		if gameInstance.IsGameOver() {
 			gameInstance.Reset()
 		}
-		gameInstance.Next()
+		botInstance.AnnounceTurn(gameInstance.Next(context.Background()))
 	}
 }
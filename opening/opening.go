@@ -0,0 +1,80 @@
+// Package opening names the opening of a game with its ECO code.
+//
+// eco.tsv is the opening table of the lichess chess-openings project, as
+// bundled with github.com/notnil/chess. Openings are matched by position,
+// so transpositions are recognised as well.
+package opening
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strings"
+	"sync"
+
+	"github.com/notnil/chess"
+)
+
+//go:embed eco.tsv
+var ecoData string
+
+// Opening is a named opening of the Encyclopaedia of Chess Openings.
+type Opening struct {
+	Code string // ECO code, e.g. "B90"
+	Name string // e.g. "Sicilian Defense: Najdorf Variation"
+}
+
+func (o *Opening) String() string {
+	return o.Code + " " + o.Name
+}
+
+var (
+	loadOnce   sync.Once
+	byPosition map[string]*Opening
+)
+
+// load indexes the table by position. The first entry of a position wins.
+func load() {
+	byPosition = make(map[string]*Opening)
+
+	r := csv.NewReader(strings.NewReader(ecoData))
+	r.Comma = '\t'
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	for i, row := range records {
+		if i == 0 || len(row) < 5 {
+			continue
+		}
+		key := positionKey(row[4])
+		if _, ok := byPosition[key]; !ok {
+			byPosition[key] = &Opening{Code: row[0], Name: row[1]}
+		}
+	}
+}
+
+// positionKey keeps the placement, side to move and castling rights of a FEN.
+// The en passant square is left out since the table only sets it when a capture is possible.
+func positionKey(fen string) string {
+	fields := strings.Fields(fen)
+	if len(fields) > 3 {
+		fields = fields[:3]
+	}
+	return strings.Join(fields, " ")
+}
+
+// Find returns the most specific opening reached by the game, or nil.
+// Later positions are tried first, so the name follows the game as long as it stays in the table.
+func Find(g *chess.Game) *Opening {
+	loadOnce.Do(load)
+
+	positions := g.Positions()
+	for i := len(positions) - 1; i > 0; i-- {
+		if o, ok := byPosition[positionKey(positions[i].String())]; ok {
+			return o
+		}
+	}
+	return nil
+}
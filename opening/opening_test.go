@@ -0,0 +1,63 @@
+package opening
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves string
+		code  string
+		title string // empty if no opening is found
+	}{
+		{"no moves", "", "", ""},
+		{"Ruy Lopez", "e4 e5 Nf3 Nc6 Bb5", "C60", "Ruy Lopez"},
+		{"Najdorf", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6", "B90", "Sicilian Defense: Najdorf Variation"},
+		// The knight comes out before the pawn, the position is the same.
+		{"Najdorf by transposition", "Nf3 c5 e4 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6", "B90", "Sicilian Defense: Najdorf Variation"},
+		{"Queen's Gambit Declined by transposition", "c4 e6 d4 d5", "D30", "Queen's Gambit Declined"},
+		// The deepest position of the table names the game...
+		{"deepest match", "e4 c5 Nf3 d6 d4 cxd4 Nxd4 Nf6 Nc3 a6 Be3 Ng4", "B90", "Sicilian Defense: Najdorf Variation, English Attack, Anti-English"},
+		// ...and stays after the game leaves the table.
+		{"out of the table", "e4 e5 Nf3 Nc6 Bb5 h6 Ba4 g5 h4 Rh7", "C60", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := chess.NewGame()
+			for _, move := range strings.Fields(test.moves) {
+				if err := g.MoveStr(move); err != nil {
+					t.Fatalf("move %s: %v", move, err)
+				}
+			}
+
+			o := Find(g)
+			switch {
+			case test.code == "":
+				if o != nil {
+					t.Errorf("Find = %v, want nil", o)
+				}
+			case o == nil:
+				t.Errorf("Find = nil, want %s %s", test.code, test.title)
+			case o.Code != test.code || (test.title != "" && o.Name != test.title):
+				t.Errorf("Find = %v, want %s %s", o, test.code, test.title)
+			}
+		})
+	}
+}
+
+func TestPositionKey(t *testing.T) {
+	// The en passant square and move counters do not matter.
+	a := positionKey("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	b := positionKey("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq -")
+	if a != b {
+		t.Errorf("keys differ: %q and %q", a, b)
+	}
+	// Castling rights do.
+	if c := positionKey("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b Kkq -"); c == a {
+		t.Errorf("castling rights are ignored: %q", c)
+	}
+}
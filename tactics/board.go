@@ -0,0 +1,148 @@
+package tactics
+
+import (
+	"hunsuChess/engine"
+
+	"github.com/notnil/chess"
+)
+
+// board is a mailbox copy of a position, cheap to modify during exchanges.
+type board [64]chess.Piece
+
+var (
+	knightSteps = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps   = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	rookDirs    = [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	bishopDirs  = [][2]int{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+)
+
+func newBoard(pos *chess.Position) *board {
+	var b board
+	for sq := 0; sq < 64; sq++ {
+		b[sq] = pos.Board().Piece(chess.Square(sq))
+	}
+	return &b
+}
+
+// offset returns the square moved by df files and dr ranks, or false if it leaves the board.
+func offset(sq int, df int, dr int) (int, bool) {
+	f, r := sq%8+df, sq/8+dr
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return 0, false
+	}
+	return r*8 + f, true
+}
+
+// attackers returns the squares of the pieces of the color attacking sq.
+func (b *board) attackers(sq int, c chess.Color) []int {
+	var result []int
+	is := func(s int, types ...chess.PieceType) bool {
+		p := b[s]
+		if p == chess.NoPiece || p.Color() != c {
+			return false
+		}
+		for _, t := range types {
+			if p.Type() == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	// A pawn attacks diagonally forward, so look diagonally backward from sq.
+	dr := -1
+	if c == chess.Black {
+		dr = 1
+	}
+	for _, df := range []int{-1, 1} {
+		if s, ok := offset(sq, df, dr); ok && is(s, chess.Pawn) {
+			result = append(result, s)
+		}
+	}
+	for _, step := range knightSteps {
+		if s, ok := offset(sq, step[0], step[1]); ok && is(s, chess.Knight) {
+			result = append(result, s)
+		}
+	}
+	for _, step := range kingSteps {
+		if s, ok := offset(sq, step[0], step[1]); ok && is(s, chess.King) {
+			result = append(result, s)
+		}
+	}
+	for _, dir := range rookDirs {
+		if s, ok := b.firstPiece(sq, dir); ok && is(s, chess.Rook, chess.Queen) {
+			result = append(result, s)
+		}
+	}
+	for _, dir := range bishopDirs {
+		if s, ok := b.firstPiece(sq, dir); ok && is(s, chess.Bishop, chess.Queen) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// firstPiece returns the first occupied square from sq in the direction.
+func (b *board) firstPiece(sq int, dir [2]int) (int, bool) {
+	for {
+		next, ok := offset(sq, dir[0], dir[1])
+		if !ok {
+			return 0, false
+		}
+		if b[next] != chess.NoPiece {
+			return next, true
+		}
+		sq = next
+	}
+}
+
+// see is the static exchange evaluation of the side c capturing on sq:
+// the material it wins if both sides keep recapturing with their cheapest piece
+// and may stop whenever continuing loses material.
+func (b *board) see(sq int, c chess.Color) int {
+	attacker, ok := b.cheapestAttacker(sq, c)
+	if !ok || b[sq] == chess.NoPiece {
+		return 0
+	}
+	captured := value(b[sq])
+	if b[sq].Type() == chess.King {
+		// Capturing the king ends the exchange, it only happens when the king is the last defender.
+		return captured
+	}
+
+	next := *b
+	next[sq] = next[attacker]
+	next[attacker] = chess.NoPiece
+	gain := captured - next.see(sq, c.Other())
+	if gain < 0 {
+		return 0
+	}
+	return gain
+}
+
+func (b *board) cheapestAttacker(sq int, c chess.Color) (int, bool) {
+	best, found := 0, false
+	for _, s := range b.attackers(sq, c) {
+		if !found || value(b[s]) < value(b[best]) {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+func (b *board) king(c chess.Color) (int, bool) {
+	for sq, p := range b {
+		if p.Type() == chess.King && p.Color() == c {
+			return sq, true
+		}
+	}
+	return 0, false
+}
+
+// value of a piece in centipawns, the king counting as priceless.
+func value(p chess.Piece) int {
+	if p.Type() == chess.King {
+		return 100000
+	}
+	return engine.PieceValue(p.Type())
+}
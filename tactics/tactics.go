@@ -0,0 +1,264 @@
+// Package tactics spots simple tactical motifs in a position without searching:
+// loose pieces, pieces attacked by cheaper ones, pins and mate-in-one threats.
+package tactics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// Kind is the motif a hint points out.
+type Kind int
+
+const (
+	// Hanging is an attacked piece nobody defends.
+	Hanging Kind = iota
+	// Attacked is a defended piece that still loses material to the exchange,
+	// usually because a cheaper piece attacks it.
+	Attacked
+	// Pinned is a piece that cannot leave the line between an enemy slider
+	// and its king or a more valuable piece.
+	Pinned
+	// MateThreat is a move that would mate at once.
+	MateThreat
+)
+
+// Hint is one motif found in a position.
+type Hint struct {
+	Kind Kind
+	// Color is the side the motif is bad for: the owner of the hanging,
+	// attacked or pinned piece, or the side that would be mated.
+	Color chess.Color
+	// Square is the square of the piece in trouble, or the mated king.
+	Square chess.Square
+	Piece  chess.Piece
+	// From holds the attackers, or the pinning piece and the piece behind the pinned one.
+	From []chess.Square
+	// Loss is what the exchange on Square costs its owner, in centipawns.
+	Loss int
+	// Behind is the piece a Pinned piece shields.
+	Behind chess.Piece
+	// Move is the mating move of a MateThreat, in UCI.
+	Move string
+}
+
+// Analyze returns the motifs of both sides, the side to move first.
+func Analyze(pos *chess.Position) []Hint {
+	b := newBoard(pos)
+	us := pos.Turn()
+
+	var hints []Hint
+	for _, c := range []chess.Color{us, us.Other()} {
+		hints = append(hints, b.loosePieces(c)...)
+		hints = append(hints, b.pins(c)...)
+	}
+	hints = append(hints, mateThreats(pos, b)...)
+	return hints
+}
+
+// loosePieces finds the pieces of c that lose material if the opponent captures them.
+func (b *board) loosePieces(c chess.Color) []Hint {
+	var hints []Hint
+	for sq, p := range b {
+		if p == chess.NoPiece || p.Color() != c || p.Type() == chess.King {
+			continue
+		}
+		attackers := b.attackers(sq, c.Other())
+		if len(attackers) == 0 {
+			continue
+		}
+		loss := b.see(sq, c.Other())
+		if loss <= 0 {
+			continue
+		}
+		kind := Attacked
+		if len(b.attackers(sq, c)) == 0 {
+			kind = Hanging
+		}
+		hints = append(hints, Hint{
+			Kind:   kind,
+			Color:  c,
+			Square: chess.Square(sq),
+			Piece:  p,
+			From:   squares(attackers),
+			Loss:   loss,
+		})
+	}
+	return hints
+}
+
+// pins finds the pieces of c pinned by an enemy rook, bishop or queen.
+func (b *board) pins(c chess.Color) []Hint {
+	var hints []Hint
+	for sq, p := range b {
+		if p == chess.NoPiece || p.Color() == c {
+			continue
+		}
+		var dirs [][2]int
+		switch p.Type() {
+		case chess.Rook:
+			dirs = rookDirs
+		case chess.Bishop:
+			dirs = bishopDirs
+		case chess.Queen:
+			dirs = append(append(dirs, rookDirs...), bishopDirs...)
+		default:
+			continue
+		}
+
+		for _, dir := range dirs {
+			first, ok := b.firstPiece(sq, dir)
+			if !ok || b[first].Color() != c || b[first].Type() == chess.King {
+				continue
+			}
+			behind, ok := b.firstPiece(first, dir)
+			if !ok || b[behind].Color() != c {
+				continue
+			}
+			// Only a pin if the piece behind is worth more than the pinner,
+			// otherwise the pinned piece can step aside without loss.
+			if b[behind].Type() != chess.King && value(b[behind]) <= value(p) {
+				continue
+			}
+			if b[behind].Type() != chess.King && value(b[first]) >= value(b[behind]) {
+				continue
+			}
+			hints = append(hints, Hint{
+				Kind:   Pinned,
+				Color:  c,
+				Square: chess.Square(first),
+				Piece:  b[first],
+				From:   []chess.Square{chess.Square(sq), chess.Square(behind)},
+				Behind: b[behind],
+			})
+		}
+	}
+	return hints
+}
+
+// mateThreats finds the mates in one of the side to move, and those the
+// opponent would have if the side to move passed.
+func mateThreats(pos *chess.Position, b *board) []Hint {
+	us := pos.Turn()
+	hints := matesInOne(pos, b)
+
+	king, ok := b.king(us)
+	if !ok || len(b.attackers(king, us.Other())) > 0 {
+		// In check there is no passing, the threat is already on the board.
+		return hints
+	}
+	fields := strings.Fields(pos.String())
+	fields[1] = "w"
+	if us == chess.White {
+		fields[1] = "b"
+	}
+	fields[3] = "-"
+	fen, err := chess.FEN(strings.Join(fields, " "))
+	if err != nil {
+		return hints
+	}
+	return append(hints, matesInOne(chess.NewGame(fen).Position(), b)...)
+}
+
+func matesInOne(pos *chess.Position, b *board) []Hint {
+	them := pos.Turn().Other()
+	king, ok := b.king(them)
+	if !ok {
+		return nil
+	}
+
+	var hints []Hint
+	for _, m := range pos.ValidMoves() {
+		if pos.Update(m).Status() != chess.Checkmate {
+			continue
+		}
+		hints = append(hints, Hint{
+			Kind:   MateThreat,
+			Color:  them,
+			Square: chess.Square(king),
+			Piece:  b[king],
+			From:   []chess.Square{m.S1(), m.S2()},
+			Move:   chess.UCINotation{}.Encode(pos, m),
+		})
+	}
+	return hints
+}
+
+func squares(sqs []int) []chess.Square {
+	result := make([]chess.Square, len(sqs))
+	for i, sq := range sqs {
+		result[i] = chess.Square(sq)
+	}
+	return result
+}
+
+var pieceNames = map[chess.PieceType]string{
+	chess.King:   "킹",
+	chess.Queen:  "퀸",
+	chess.Rook:   "룩",
+	chess.Bishop: "비숍",
+	chess.Knight: "나이트",
+	chess.Pawn:   "폰",
+}
+
+func colorName(c chess.Color) string {
+	if c == chess.White {
+		return "백"
+	}
+	return "흑"
+}
+
+// String describes the hint in Korean for Discord.
+func (h Hint) String() string {
+	piece := fmt.Sprintf("%s %s(%s)", colorName(h.Color), pieceNames[h.Piece.Type()], h.Square)
+	switch h.Kind {
+	case Hanging:
+		return fmt.Sprintf("%s이(가) 보호 없이 공격받고 있습니다. (공격: %s)", piece, describeSquares(h.From))
+	case Attacked:
+		return fmt.Sprintf("%s이(가) 교환에서 %.1f점을 잃습니다. (공격: %s)", piece, float64(h.Loss)/100, describeSquares(h.From))
+	case Pinned:
+		return fmt.Sprintf("%s이(가) %s에 핀 당해 뒤의 %s을(를) 지키고 있습니다.", piece, h.From[0], pieceNames[h.Behind.Type()])
+	}
+	return fmt.Sprintf("%s 킹이 %s 한 수에 체크메이트 당할 수 있습니다.", colorName(h.Color), h.Move)
+}
+
+func describeSquares(sqs []chess.Square) string {
+	names := make([]string, len(sqs))
+	for i, sq := range sqs {
+		names[i] = sq.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Forked returns the enemy pieces the piece on sq could win by capturing
+// them: the king, more valuable pieces and undefended ones. Two or more of
+// them make a fork.
+func Forked(pos *chess.Position, sq chess.Square) []chess.Square {
+	b := newBoard(pos)
+	p := b[sq]
+	if p == chess.NoPiece {
+		return nil
+	}
+
+	var targets []chess.Square
+	for target, q := range b {
+		if q == chess.NoPiece || q.Color() == p.Color() {
+			continue
+		}
+		attacked := false
+		for _, from := range b.attackers(target, p.Color()) {
+			if from == int(sq) {
+				attacked = true
+			}
+		}
+		if !attacked {
+			continue
+		}
+		if q.Type() == chess.King || value(q) > value(p) || len(b.attackers(target, q.Color())) == 0 {
+			targets = append(targets, chess.Square(target))
+		}
+	}
+	return targets
+}
@@ -0,0 +1,94 @@
+package tactics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func mustPosition(t *testing.T, fen string) *chess.Position {
+	t.Helper()
+	pos := &chess.Position{}
+	if err := pos.UnmarshalText([]byte(fen)); err != nil {
+		t.Fatalf("bad FEN %q: %v", fen, err)
+	}
+	return pos
+}
+
+func TestForked(t *testing.T) {
+	tests := []struct {
+		name    string
+		fen     string
+		sq      chess.Square
+		targets []chess.Square
+	}{
+		{"knight fork", "r3k3/2N5/8/8/8/8/8/4K3 b - - 0 1", chess.C7, []chess.Square{chess.A8, chess.E8}},
+		{"pawn fork", "4k3/8/3n1n2/4P3/8/8/8/4K3 b - - 0 1", chess.E5, []chess.Square{chess.D6, chess.F6}},
+		{"defended pawn", "4k3/3p4/8/8/8/8/8/3QK3 w - - 0 1", chess.D1, nil},
+		{"undefended pawn", "6k1/3p4/8/8/8/8/8/3QK3 w - - 0 1", chess.D1, []chess.Square{chess.D7}},
+		{"empty square", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", chess.D4, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Forked(mustPosition(t, test.fen), test.sq); !reflect.DeepEqual(got, test.targets) {
+				t.Errorf("Forked = %v, want %v", got, test.targets)
+			}
+		})
+	}
+}
+
+func TestPins(t *testing.T) {
+	tests := []struct {
+		name   string
+		fen    string
+		pinned chess.Square // 0 if nothing is pinned
+		from   []chess.Square
+	}{
+		{"knight to king", "4k3/8/2n5/1B6/8/8/8/4K3 b - - 0 1", chess.C6, []chess.Square{chess.B5, chess.E8}},
+		{"knight to queen", "4q1k1/8/8/4n3/8/8/8/4R1K1 b - - 0 1", chess.E5, []chess.Square{chess.E1, chess.E8}},
+		{"cheaper piece behind", "4n1k1/8/8/4r3/8/8/8/4Q1K1 b - - 0 1", 0, nil},
+		{"pinner worth the piece behind", "4r1k1/8/8/4n3/8/8/8/4R1K1 b - - 0 1", 0, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pins []Hint
+			for _, hint := range Analyze(mustPosition(t, test.fen)) {
+				if hint.Kind == Pinned {
+					pins = append(pins, hint)
+				}
+			}
+			if test.from == nil {
+				if len(pins) != 0 {
+					t.Errorf("pins %+v, want none", pins)
+				}
+				return
+			}
+			if len(pins) != 1 || pins[0].Square != test.pinned || !reflect.DeepEqual(pins[0].From, test.from) || pins[0].Color != chess.Black {
+				t.Errorf("pins %+v, want black %s pinned from %v", pins, test.pinned, test.from)
+			}
+		})
+	}
+}
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		sq   chess.Square
+		want int
+	}{
+		{"undefended knight", "4k3/8/8/3n4/8/8/8/3RK3 w - - 0 1", chess.D5, 320},
+		{"knight takes a defended pawn", "4k3/8/2p5/3p4/8/4N3/8/4K3 w - - 0 1", chess.D5, 0},
+		{"pawn takes a defended knight", "4k3/8/2p5/3n4/4P3/8/8/4K3 w - - 0 1", chess.D5, 220},
+		{"doubled rooks", "3rk3/8/8/3r4/8/8/3R4/3RK3 w - - 0 1", chess.D5, 500},
+		{"nothing attacks", "4k3/8/8/3n4/8/8/8/4K3 w - - 0 1", chess.D5, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := newBoard(mustPosition(t, test.fen)).see(int(test.sq), chess.White); got != test.want {
+				t.Errorf("see = %d, want %d", got, test.want)
+			}
+		})
+	}
+}